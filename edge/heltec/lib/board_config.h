@@ -20,3 +20,13 @@
 #ifndef RST_OLED
 #define RST_OLED 21
 #endif
+
+// PRG (BOOT) push button, active-low with on-board pull-up
+#ifndef PRG_BUTTON_PIN
+#define PRG_BUTTON_PIN 0
+#endif
+
+// White user LED, active-high
+#ifndef STATUS_LED_PIN
+#define STATUS_LED_PIN 35
+#endif
@@ -37,7 +37,11 @@ namespace Messaging {
     // Sub-types for command messages
     enum class CommandType : uint8_t {
         // Add other command types here
-        ResetWaterVolume = 0x01
+        ResetWaterVolume = 0x01,
+        // Relay -> remote: [0x05][delay ms, u32 BE][cycle ms, u32 BE]
+        // Send the next uplink after "delay", then once per "cycle".
+        // 0x02-0x04 are taken by the LoRaWAN commands in svc_lorawan.h
+        SetUplinkSlot = 0x05
     };
 
     class Message {
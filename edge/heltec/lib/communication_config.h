@@ -5,6 +5,7 @@
 
 #include <stdint.h>
 #include "common_message_types.h"
+#include "lora_region.h"
 
 // MQTT Configuration
 struct MqttConfig {
@@ -18,6 +19,7 @@ struct MqttConfig {
     const char* deviceTopic = nullptr;  // Optional device-specific topic (defaults to deviceId)
     uint8_t qos = 0;                    // QoS level (0/1)
     bool retain = false;                // Retain flag
+    const char* statusTopic = nullptr;  // Optional suffix for a retained "online"/"offline" status, also used as last will
     
     // Reliability settings
     uint32_t connectionTimeoutMs = 10000;    // Connection timeout
@@ -47,7 +49,7 @@ struct UsbConfig {
 // LoRa Configuration
 struct LoraConfig {
     bool enableLora = true;            // Enable LoRa communication
-    uint32_t frequency = 868000000UL;  // Operating frequency (Hz)
+    uint32_t frequency = LORA_COMM_RF_FREQUENCY;  // Operating frequency (Hz), from the region macro
     uint8_t txPower = 14;              // Transmit power (dBm)
     uint8_t spreadingFactor = 7;       // Spreading factor (6-12)
     uint8_t codingRate = 1;            // Coding rate (1=4/5, 2=4/6, 3=4/7, 4=4/8)
@@ -61,6 +63,7 @@ struct LoraConfig {
     uint32_t ackTimeoutMs = 1500;      // ACK timeout
     uint8_t maxRetries = 4;            // Maximum retry attempts
     uint32_t pingIntervalMs = 30000;   // Ping interval (slave mode)
+    uint16_t dutyCyclePermille = 0;    // Max TX airtime per hour in 1/1000 (10 = 1%), 0 = unlimited
 
     // Advanced timing
     uint32_t masterTtlMs = 15000;      // Master TTL for peer tracking
@@ -60,8 +60,9 @@ DeviceConfig DeviceConfigFactory::createRelayConfig(uint8_t deviceId) {
     cfg.communication.usb.baudRate = 115200;
 
     cfg.communication.lora.enableLora = true;
-    cfg.communication.lora.frequency = 868000000UL;
+    cfg.communication.lora.frequency = LORA_COMM_RF_FREQUENCY;
     cfg.communication.lora.txPower = 14;
+    cfg.communication.lora.dutyCyclePermille = LORA_COMM_DUTY_CYCLE_PERMILLE; // 1% on EU868, none on US915
 
     // Set up routing rules for relay: LoRa -> WiFi, LoRa -> USB, Telemetry -> Screen
     cfg.communication.routing.enableRouting = true;
@@ -89,7 +90,7 @@ DeviceConfig DeviceConfigFactory::createRemoteConfig(uint8_t deviceId) {
     cfg.communication.wifi.enableWifi = false;  // Remotes typically don't need WiFi
 
     cfg.communication.lora.enableLora = true;
-    cfg.communication.lora.frequency = 868000000UL;
+    cfg.communication.lora.frequency = LORA_COMM_RF_FREQUENCY;
     cfg.communication.lora.txPower = 14;
 
     // Set up routing rules for remote: Telemetry -> LoRa
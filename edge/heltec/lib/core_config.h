@@ -62,6 +62,21 @@ struct RelayConfig : DeviceConfig {
     uint32_t peerMonitorIntervalMs = 2000;
     uint32_t peerTimeoutMs = 120000; // 2 minutes
     uint8_t maxPeers = 16;
+    uint32_t statusPageIntervalMs = 4000; // OLED status page rotation, 0 = manual only
+    uint32_t displayDimAfterMs = 60000;        // OLED dims without a button press for this long, 0 = never
+    uint32_t displaySleepAfterMs = 5 * 60 * 1000; // ...and turns off, 0 = never; errors keep it on
+    uint32_t displayShiftIntervalMs = 60000;   // Burn-in protection: move the image by a pixel, 0 = off
+    uint32_t neighborReportIntervalMs = 60000; // Neighbor table dump over serial
+    uint32_t watchdogTimeoutMs = 60000;        // Task WDT; must exceed the longest blocking call (MQTT connect)
+    uint32_t radioSilenceResetMs = 5 * 60 * 1000; // Reinit the radio if no frame is heard for this long
+    uint32_t statusReportIntervalMs = 60000;   // Relay health published to MQTT
+    uint8_t lowBatteryPercent = 20;            // Low-battery alarm; clears 5% above
+    uint8_t logStreamLevel = 1;                // Lowest Logger::Level streamed to MQTT (0=Error, 1=Warn, ...); 0xFF = off
+    uint16_t logStreamMaxPerMinute = 20;       // Rate limit for streamed log lines
+    uint16_t selfTestToneSeconds = 2;          // CW tone during the PRG-at-boot self-test, 0 = skip
+    uint8_t uplinkSlotCount = 0;               // Uplink time slots handed to remotes, 0 = off (remotes send when they like)
+    uint32_t uplinkSlotCycleMs = 60000;        // Slot cycle; remotes report once per cycle while slotted
+    uint32_t uplinkSlotGuardMs = 1500;         // Remotes send this long after their slot opens
 
     RelayConfig() = default;
 
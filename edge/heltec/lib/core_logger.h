@@ -1,6 +1,7 @@
 // Header-only simple logger with verbosity, OLED overlay, and debug routing capabilities
 // - Centralizes Serial and OLED logging
 // - Supports temporary debug overlays with duration
+// - Optional sink to forward lines elsewhere (e.g. MQTT log streaming)
 // - Default: verbose=false; level=Info; Serial on
 
 #pragma once
@@ -23,6 +24,12 @@ inline const char *g_deviceId = nullptr;
 inline char g_deviceIdBuf[16] = {0};
 inline OverlayCtx g_overlayCtx; // reused buffer
 
+// Optional second destination for formatted lines (e.g. MQTT log streaming).
+// Called from whichever task logs, so it must be quick and must not log itself.
+using SinkFn = void (*)(Level level, const char *tag, const char *message);
+inline SinkFn g_sink = nullptr;
+inline Level g_sinkLevel = Level::Warn;
+
 // Forward declarations to allow usage before definitions
 inline void setLevel(Level level);
 inline void setVerbose(bool verbose);
@@ -61,6 +68,11 @@ inline bool safeInitialize(const char *deviceId) {
 
 inline void setLevel(Level level) { g_level = level; }
 inline void setVerbose(bool verbose) { g_verbose = verbose; }
+// Independent of setLevel(): the sink gets every line at or above minLevel
+inline void setSink(SinkFn sink, Level minLevel) {
+  g_sinkLevel = minLevel;
+  g_sink = sink;
+}
 
 inline bool isEnabled(Level level) {
   if (g_verbose) return true;
@@ -68,16 +80,20 @@ inline bool isEnabled(Level level) {
 }
 
 inline void vprintf(Level level, const char *tag, const char *fmt, va_list ap) {
-  if (!isEnabled(level)) return;
-  if (g_serialEnabled) {
-    char buf[160];
-    int len = vsnprintf(buf, sizeof(buf) - 1, fmt, ap);
-    if (len >= 0 && len < (int)sizeof(buf) - 1) {
-      buf[len] = '\0'; // Ensure null termination
-    } else {
-      buf[sizeof(buf) - 1] = '\0'; // Safety fallback
-    }
+  const bool toSerial = g_serialEnabled && isEnabled(level);
+  const SinkFn sink = g_sink;
+  const bool toSink = sink != nullptr && static_cast<uint8_t>(level) <= static_cast<uint8_t>(g_sinkLevel);
+  if (!toSerial && !toSink) return;
+
+  char buf[160];
+  int len = vsnprintf(buf, sizeof(buf) - 1, fmt, ap);
+  if (len >= 0 && len < (int)sizeof(buf) - 1) {
+    buf[len] = '\0'; // Ensure null termination
+  } else {
+    buf[sizeof(buf) - 1] = '\0'; // Safety fallback
+  }
 
+  if (toSerial) {
     // Only print if Serial is available
     if (Serial) {
       Serial.print('[');
@@ -91,6 +107,9 @@ inline void vprintf(Level level, const char *tag, const char *fmt, va_list ap) {
       Serial.println(buf);
     }
   }
+  if (toSink) {
+    sink(level, tag, buf);
+  }
 }
 
 inline void printf(Level level, const char *tag, const char *fmt, ...) {
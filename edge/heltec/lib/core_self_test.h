@@ -0,0 +1,28 @@
+#pragma once
+
+#include <stdint.h>
+#include "hal_display.h"
+#include "hal_lora.h"
+
+// Bench self-test, run at boot while PRG is held.
+// Prints one line per check over Serial for test fixtures to parse:
+//   SELFTEST <check> PASS|FAIL [detail]
+//   SELFTEST RESULT PASS|FAIL
+//   SELFTEST cw SENT|SKIP <freq>Hz <txp>dBm <seconds>s
+// and shows the checks on the OLED. run() leaves the radio asleep; the LoRa
+// HAL reinitializes it when it begins, and only then is the CW tone sent, so
+// it goes through the HAL's duty-cycle budget and doesn't hold up boot.
+class CoreSelfTest {
+public:
+    // cwSeconds = 0 skips the test tone
+    bool run(IDisplayHal& display, uint16_t cwSeconds);
+    // Call after the LoRa HAL has begun; does nothing if run() found no radio
+    void sendTestTone(ILoRaHal& lora, uint32_t nowMs);
+
+private:
+    bool checkOled();
+    bool checkRadioSpi();
+
+    bool _radioOk = false;
+    uint16_t _cwSeconds = 0;
+};
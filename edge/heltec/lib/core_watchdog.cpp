@@ -0,0 +1,43 @@
+#include "core_watchdog.h"
+#include "core_logger.h"
+#include <esp_idf_version.h>
+#include <esp_system.h>
+#include <esp_task_wdt.h>
+
+void CoreWatchdog::begin(uint32_t timeoutMs) {
+#if ESP_IDF_VERSION_MAJOR >= 5
+    // Arduino core 3.x starts the task WDT itself; adjust it instead
+    esp_task_wdt_config_t wdtConfig = {};
+    wdtConfig.timeout_ms = timeoutMs;
+    wdtConfig.idle_core_mask = 0;
+    wdtConfig.trigger_panic = true;
+    esp_err_t err = esp_task_wdt_reconfigure(&wdtConfig);
+    if (err != ESP_OK) {
+        err = esp_task_wdt_init(&wdtConfig);
+    }
+#else
+    // Updates the timeout if the core already initialized the WDT
+    esp_err_t err = esp_task_wdt_init((timeoutMs + 999) / 1000, true);
+#endif
+    _enabled = (err == ESP_OK);
+    if (_enabled) {
+        LOGI("SYS", "Watchdog armed (%lu ms)", (unsigned long)timeoutMs);
+    } else {
+        LOGW("SYS", "Watchdog init failed (%d)", (int)err);
+    }
+}
+
+void CoreWatchdog::subscribeCurrentTask() {
+    if (!_enabled) return;
+    esp_task_wdt_add(nullptr);
+}
+
+void CoreWatchdog::feed() {
+    if (!_enabled) return;
+    esp_task_wdt_reset();
+}
+
+bool CoreWatchdog::lastResetWasWatchdog() {
+    const esp_reset_reason_t reason = esp_reset_reason();
+    return reason == ESP_RST_TASK_WDT || reason == ESP_RST_INT_WDT || reason == ESP_RST_WDT;
+}
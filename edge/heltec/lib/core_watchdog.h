@@ -0,0 +1,18 @@
+#pragma once
+
+#include <stdint.h>
+
+// ESP32 task watchdog. Each task that calls subscribeCurrentTask() must call
+// feed() at least once per timeout, otherwise the chip panics and resets.
+class CoreWatchdog {
+public:
+    void begin(uint32_t timeoutMs);
+    void subscribeCurrentTask();
+    void feed();
+
+    // True if the previous reset was caused by any of the hardware watchdogs
+    static bool lastResetWasWatchdog();
+
+private:
+    bool _enabled = false;
+};
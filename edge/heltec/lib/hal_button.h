@@ -0,0 +1,56 @@
+#pragma once
+
+#include <Arduino.h>
+#include <stdint.h>
+
+class IButtonHal {
+public:
+    virtual ~IButtonHal() = default;
+
+    virtual void begin() = 0;
+    // Returns true exactly once per debounced press
+    virtual bool wasPressed(uint32_t nowMs) = 0;
+    // Raw level, e.g. to check whether the button is held at boot
+    virtual bool isDown() const = 0;
+};
+
+class GpioButtonHal : public IButtonHal {
+public:
+    explicit GpioButtonHal(int pin, bool activeLow = true, uint32_t debounceMs = 30)
+        : _pin(pin), _activeLow(activeLow), _debounceMs(debounceMs) {}
+
+    void begin() override {
+        pinMode(_pin, _activeLow ? INPUT_PULLUP : INPUT);
+        _stablePressed = readRaw();
+        _lastRaw = _stablePressed;
+    }
+
+    bool wasPressed(uint32_t nowMs) override {
+        const bool raw = readRaw();
+        if (raw != _lastRaw) {
+            _lastRaw = raw;
+            _lastChangeMs = nowMs;
+            return false;
+        }
+        if (raw == _stablePressed || nowMs - _lastChangeMs < _debounceMs) {
+            return false;
+        }
+        _stablePressed = raw;
+        return raw; // Report the press edge only
+    }
+
+    bool isDown() const override { return readRaw(); }
+
+private:
+    bool readRaw() const {
+        const int level = digitalRead(_pin);
+        return _activeLow ? (level == LOW) : (level == HIGH);
+    }
+
+    int _pin;
+    bool _activeLow;
+    uint32_t _debounceMs;
+    bool _stablePressed = false;
+    bool _lastRaw = false;
+    uint32_t _lastChangeMs = 0;
+};
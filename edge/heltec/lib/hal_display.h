@@ -27,6 +27,12 @@ public:
     virtual void fillRect(int16_t x, int16_t y, int16_t w, int16_t h) = 0;
     virtual void drawLine(int16_t x0, int16_t y0, int16_t x1, int16_t y1) = 0;
     virtual void setPixel(int16_t x, int16_t y) = 0;
+
+    // Panel power and brightness (0-255), for dimming and sleep
+    virtual void setPower(bool on) = 0;
+    virtual void setBrightness(uint8_t level) = 0;
+    // Offsets every draw call to spread OLED wear; a pixel or two is enough
+    virtual void setPixelShift(int8_t dx, int8_t dy) = 0;
 };
 
 class OledDisplayHal : public IDisplayHal {
@@ -45,9 +51,14 @@ public:
     void fillRect(int16_t x, int16_t y, int16_t w, int16_t h) override;
     void drawLine(int16_t x0, int16_t y0, int16_t x1, int16_t y1) override;
     void setPixel(int16_t x, int16_t y) override;
+    void setPower(bool on) override;
+    void setBrightness(uint8_t level) override;
+    void setPixelShift(int8_t dx, int8_t dy) override;
 
 private:
     OledDisplay _oled;
+    int8_t _dx = 0;
+    int8_t _dy = 0;
 };
 
 OledDisplayHal::OledDisplayHal() : _oled() {}
@@ -77,29 +88,46 @@ void OledDisplayHal::setTextAlignment(int alignment) {
 }
 
 void OledDisplayHal::drawString(int16_t x, int16_t y, const String& text) {
-    _oled.getDisplay().drawString(x, y, text);
+    _oled.getDisplay().drawString(x + _dx, y + _dy, text);
 }
 
 void OledDisplayHal::drawXbm(int16_t x, int16_t y, int16_t width, int16_t height, const uint8_t* xbm) {
-    _oled.getDisplay().drawXbm(x, y, width, height, xbm);
+    _oled.getDisplay().drawXbm(x + _dx, y + _dy, width, height, xbm);
 }
 
 void OledDisplayHal::drawHorizontalLine(int16_t x, int16_t y, int16_t length) {
-    _oled.getDisplay().drawHorizontalLine(x, y, length);
+    _oled.getDisplay().drawHorizontalLine(x + _dx, y + _dy, length);
 }
 
 void OledDisplayHal::drawRect(int16_t x, int16_t y, int16_t w, int16_t h) {
-    _oled.getDisplay().drawRect(x, y, w, h);
+    _oled.getDisplay().drawRect(x + _dx, y + _dy, w, h);
 }
 
 void OledDisplayHal::fillRect(int16_t x, int16_t y, int16_t w, int16_t h) {
-    _oled.getDisplay().fillRect(x, y, w, h);
+    _oled.getDisplay().fillRect(x + _dx, y + _dy, w, h);
 }
 
 void OledDisplayHal::drawLine(int16_t x0, int16_t y0, int16_t x1, int16_t y1) {
-    _oled.getDisplay().drawLine(x0, y0, x1, y1);
+    _oled.getDisplay().drawLine(x0 + _dx, y0 + _dy, x1 + _dx, y1 + _dy);
 }
 
 void OledDisplayHal::setPixel(int16_t x, int16_t y) {
-    _oled.getDisplay().setPixel(x, y);
+    _oled.getDisplay().setPixel(x + _dx, y + _dy);
+}
+
+void OledDisplayHal::setPower(bool on) {
+    if (on) {
+        _oled.getDisplay().displayOn();
+    } else {
+        _oled.getDisplay().displayOff();
+    }
+}
+
+void OledDisplayHal::setBrightness(uint8_t level) {
+    _oled.getDisplay().setBrightness(level);
+}
+
+void OledDisplayHal::setPixelShift(int8_t dx, int8_t dy) {
+    _dx = dx;
+    _dy = dy;
 }
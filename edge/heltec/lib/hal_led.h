@@ -0,0 +1,34 @@
+#pragma once
+
+#include <Arduino.h>
+#include <stdint.h>
+
+class ILedHal {
+public:
+    virtual ~ILedHal() = default;
+
+    virtual void begin() = 0;
+    virtual void set(bool on) = 0;
+};
+
+class GpioLedHal : public ILedHal {
+public:
+    explicit GpioLedHal(int pin, bool activeHigh = true) : _pin(pin), _activeHigh(activeHigh) {}
+
+    void begin() override {
+        pinMode(_pin, OUTPUT);
+        _on = true; // Force the first write
+        set(false);
+    }
+
+    void set(bool on) override {
+        if (on == _on) return;
+        _on = on;
+        digitalWrite(_pin, (on == _activeHigh) ? HIGH : LOW);
+    }
+
+private:
+    int _pin;
+    bool _activeHigh;
+    bool _on = false;
+};
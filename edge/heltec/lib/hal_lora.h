@@ -17,6 +17,12 @@ public:
     virtual void tick(uint32_t nowMs) = 0;
 
     virtual bool sendData(uint8_t destId, const uint8_t *payload, uint8_t length, bool requireAck = true) = 0;
+    // Unacknowledged, sent ahead of the outbox, dropped if not sent within maxAgeMs
+    virtual bool sendDataWithin(uint8_t destId, const uint8_t *payload, uint8_t length, uint32_t maxAgeMs) = 0;
+    // Message ID of the last successful sendData(), as later passed to the ACK/drop callbacks
+    virtual uint16_t getLastQueuedMessageId() const = 0;
+    // False while the radio doesn't answer over SPI; tick() retries init with backoff
+    virtual bool isRadioResponding() const = 0;
     virtual bool isReadyForTx() const = 0;
     virtual void resetCounters() = 0;
     
@@ -25,17 +31,51 @@ public:
     virtual void setOnMessageDropped(OnMessageDropped cb) = 0;
     virtual void setPeerTimeout(uint32_t timeoutMs) = 0;
     virtual void setVerbose(bool verbose) = 0;
+    virtual void setRxSilenceTimeout(uint32_t timeoutMs) = 0;
 
     virtual bool isConnected() const = 0;
     virtual int16_t getLastRssiDbm() const = 0;
     virtual size_t getPeerCount() const = 0;
     virtual size_t getTotalPeerCount() const = 0;
+    // Neighbor table entry by position (0..getTotalPeerCount()-1)
+    using PeerInfo = LoRaComm::PeerInfo;
+    virtual bool getPeerByIndex(size_t index, PeerInfo& out) const = 0;
 
     // Connection state management
     virtual void setMasterNodeId(uint8_t masterId) = 0;
     virtual void forceReconnect() = 0;
     using ConnectionState = LoRaComm::ConnectionState;
     virtual ConnectionState getConnectionState() const = 0;
+
+    // Radio parameters (frequency, SF, bandwidth, TX power)
+    using RadioSettings = LoRaComm::RadioSettings;
+    virtual void setRadioSettings(const RadioSettings& settings) = 0;
+    virtual RadioSettings getRadioSettings() const = 0;
+
+    // Cumulative frame counters and signal quality of the last received frame
+    using LinkStats = LoRaComm::LinkStats;
+    virtual LinkStats getLinkStats() const = 0;
+
+    // Regulatory TX airtime limit in 1/1000 of an hour (10 = 1%), 0 = unlimited
+    using DutyCycleStatus = LoRaComm::DutyCycleStatus;
+    virtual void setDutyCycleLimit(uint16_t limitPermille) = 0;
+    virtual DutyCycleStatus getDutyCycleStatus() const = 0;
+    // CW tone for bench tests, charged to the duty-cycle budget; false if refused
+    virtual bool startTestTone(uint32_t nowMs, uint16_t seconds) = 0;
+
+    // AES-128 link key (nullptr disables) and the next frame counter to send
+    virtual bool setEncryptionKey(const uint8_t* key) = 0;
+    virtual bool isEncryptionEnabled() const = 0;
+    virtual void setTxFrameCounter(uint32_t next) = 0;
+    virtual uint32_t getTxFrameCounter() const = 0;
+    // Highest frame counter accepted per sender (replay floor), seeded from flash
+    virtual void setRxFrameCounter(uint8_t srcId, uint32_t highest) = 0;
+    virtual bool getRxFrameCounter(uint8_t srcId, uint32_t& highest) const = 0;
+    // Hands back one sender whose counter moved since it was last taken
+    virtual bool takeUnsavedRxFrameCounter(uint8_t& srcId, uint32_t& highest) = 0;
+    virtual void clearRxFrameCounters() = 0;
+    // Largest payload sendData() accepts with the current link settings
+    virtual uint8_t getMaxPayload() const = 0;
 };
 
 class LoRaCommHal : public ILoRaHal {
@@ -44,6 +84,9 @@ public:
     bool begin(Mode mode, uint8_t deviceId) override;
     void tick(uint32_t nowMs) override;
     bool sendData(uint8_t targetId, const uint8_t* data, uint8_t len, bool ack) override;
+    bool sendDataWithin(uint8_t targetId, const uint8_t* data, uint8_t len, uint32_t maxAgeMs) override;
+    uint16_t getLastQueuedMessageId() const override;
+    bool isRadioResponding() const override;
     bool isReadyForTx() const override;
     void resetCounters() override;
     void setOnDataReceived(OnDataReceived cb) override;
@@ -51,16 +94,34 @@ public:
     void setOnMessageDropped(OnMessageDropped cb) override;
     void setPeerTimeout(uint32_t timeoutMs) override;
     void setVerbose(bool verbose) override;
+    void setRxSilenceTimeout(uint32_t timeoutMs) override;
     bool isConnected() const override;
     int16_t getLastRssiDbm() const override;
     size_t getPeerCount() const override;
     size_t getTotalPeerCount() const override;
+    bool getPeerByIndex(size_t index, PeerInfo& out) const override;
 
     // Connection state management
     void setMasterNodeId(uint8_t masterId) override;
     void forceReconnect() override;
     ConnectionState getConnectionState() const override;
 
+    void setRadioSettings(const RadioSettings& settings) override;
+    RadioSettings getRadioSettings() const override;
+    LinkStats getLinkStats() const override;
+    void setDutyCycleLimit(uint16_t limitPermille) override;
+    DutyCycleStatus getDutyCycleStatus() const override;
+    bool startTestTone(uint32_t nowMs, uint16_t seconds) override;
+    bool setEncryptionKey(const uint8_t* key) override;
+    bool isEncryptionEnabled() const override;
+    void setTxFrameCounter(uint32_t next) override;
+    uint32_t getTxFrameCounter() const override;
+    void setRxFrameCounter(uint8_t srcId, uint32_t highest) override;
+    bool getRxFrameCounter(uint8_t srcId, uint32_t& highest) const override;
+    bool takeUnsavedRxFrameCounter(uint8_t& srcId, uint32_t& highest) override;
+    void clearRxFrameCounters() override;
+    uint8_t getMaxPayload() const override;
+
 private:
     LoRaComm _lora;
 };
@@ -80,6 +141,18 @@ bool LoRaCommHal::sendData(uint8_t targetId, const uint8_t* data, uint8_t len, b
     return _lora.sendData(targetId, data, len, ack);
 }
 
+bool LoRaCommHal::sendDataWithin(uint8_t targetId, const uint8_t* data, uint8_t len, uint32_t maxAgeMs) {
+    return _lora.sendDataWithin(targetId, data, len, maxAgeMs);
+}
+
+uint16_t LoRaCommHal::getLastQueuedMessageId() const {
+    return _lora.getLastQueuedMessageId();
+}
+
+bool LoRaCommHal::isRadioResponding() const {
+    return _lora.isRadioResponding();
+}
+
 bool LoRaCommHal::isReadyForTx() const {
     return _lora.isReadyForTx();
 }
@@ -108,6 +181,10 @@ void LoRaCommHal::setVerbose(bool verbose) {
     _lora.setVerbose(verbose);
 }
 
+void LoRaCommHal::setRxSilenceTimeout(uint32_t timeoutMs) {
+    _lora.setRxSilenceTimeout(timeoutMs);
+}
+
 bool LoRaCommHal::isConnected() const {
     return _lora.isConnected();
 }
@@ -124,6 +201,10 @@ size_t LoRaCommHal::getTotalPeerCount() const {
     return _lora.getTotalPeerCount();
 }
 
+bool LoRaCommHal::getPeerByIndex(size_t index, PeerInfo& out) const {
+    return _lora.getPeerByIndex(index, out);
+}
+
 // Connection state management
 void LoRaCommHal::setMasterNodeId(uint8_t masterId) {
     _lora.setMasterNodeId(masterId);
@@ -136,3 +217,63 @@ void LoRaCommHal::forceReconnect() {
 LoRaCommHal::ConnectionState LoRaCommHal::getConnectionState() const {
     return _lora.getConnectionState();
 }
+
+void LoRaCommHal::setRadioSettings(const RadioSettings& settings) {
+    _lora.setRadioSettings(settings);
+}
+
+LoRaCommHal::RadioSettings LoRaCommHal::getRadioSettings() const {
+    return _lora.getRadioSettings();
+}
+
+LoRaCommHal::LinkStats LoRaCommHal::getLinkStats() const {
+    return _lora.getLinkStats();
+}
+
+void LoRaCommHal::setDutyCycleLimit(uint16_t limitPermille) {
+    _lora.setDutyCycleLimit(limitPermille);
+}
+
+LoRaCommHal::DutyCycleStatus LoRaCommHal::getDutyCycleStatus() const {
+    return _lora.getDutyCycleStatus();
+}
+
+bool LoRaCommHal::startTestTone(uint32_t nowMs, uint16_t seconds) {
+    return _lora.startTestTone(nowMs, seconds);
+}
+
+bool LoRaCommHal::setEncryptionKey(const uint8_t* key) {
+    return _lora.setEncryptionKey(key);
+}
+
+bool LoRaCommHal::isEncryptionEnabled() const {
+    return _lora.isEncryptionEnabled();
+}
+
+void LoRaCommHal::setTxFrameCounter(uint32_t next) {
+    _lora.setTxFrameCounter(next);
+}
+
+uint32_t LoRaCommHal::getTxFrameCounter() const {
+    return _lora.getTxFrameCounter();
+}
+
+void LoRaCommHal::setRxFrameCounter(uint8_t srcId, uint32_t highest) {
+    _lora.setRxFrameCounter(srcId, highest);
+}
+
+bool LoRaCommHal::getRxFrameCounter(uint8_t srcId, uint32_t& highest) const {
+    return _lora.getRxFrameCounter(srcId, highest);
+}
+
+bool LoRaCommHal::takeUnsavedRxFrameCounter(uint8_t& srcId, uint32_t& highest) {
+    return _lora.takeUnsavedRxFrameCounter(srcId, highest);
+}
+
+void LoRaCommHal::clearRxFrameCounters() {
+    _lora.clearRxFrameCounters();
+}
+
+uint8_t LoRaCommHal::getMaxPayload() const {
+    return _lora.getMaxAppPayload();
+}
@@ -20,6 +20,7 @@ public:
     // MQTT support
     virtual void setMqttConfig(const MqttPublisherConfig& config) = 0;
     virtual bool publishMqtt(const char* topicSuffix, const uint8_t* payload, uint8_t length) = 0;
+    virtual bool publishMqttRetained(const char* topicSuffix, const char* payload) = 0;
     virtual bool isMqttReady() const = 0;
     virtual bool isMqttConnected() const = 0;
     
@@ -43,6 +44,7 @@ public:
     // MQTT support
     void setMqttConfig(const MqttPublisherConfig& config) override;
     bool publishMqtt(const char* topicSuffix, const uint8_t* payload, uint8_t length) override;
+    bool publishMqttRetained(const char* topicSuffix, const char* payload) override;
     bool isMqttReady() const override;
     bool isMqttConnected() const override;
     
@@ -101,6 +103,13 @@ bool WifiManagerHal::publishMqtt(const char* topicSuffix, const uint8_t* payload
     return false;
 }
 
+bool WifiManagerHal::publishMqttRetained(const char* topicSuffix, const char* payload) {
+    if (_mqttPublisher) {
+        return _mqttPublisher->publishRetained(topicSuffix, payload);
+    }
+    return false;
+}
+
 bool WifiManagerHal::isMqttReady() const {
     return _mqttPublisher && _mqttPublisher->isReady();
 }
@@ -6,6 +6,8 @@
 //   - Slave tracks connectivity based on ACKs for recently ACK-requested messages
 // - Non-blocking; call tick(nowMs) regularly (e.g., via scheduler)
 // - Extensible framing; decoupled callbacks for received DATA and ACK events
+// - Optional duty-cycle limit: ACKs over budget are dropped, queued DATA waits
+// - Optional AES-128-CCM link encryption with frame-counter replay protection
 
 #pragma once
 
@@ -17,19 +19,11 @@
 #include "LoRaWan_APP.h"
 #include "core_logger.h"
 #include "common_message_types.h"
+#include "lora_duty_cycle.h"
+#include "lora_crypto.h"
+#include "lora_region.h"
 
 // Configuration defaults (override by defining before including this header)
-// Region selection (override by defining LORA_COMM_RF_FREQUENCY or a region macro before include)
-#ifndef LORA_COMM_RF_FREQUENCY
-  #if defined(LORA_REGION_US915)
-    #define LORA_COMM_RF_FREQUENCY 915000000UL
-  #elif defined(LORA_REGION_EU868)
-    #define LORA_COMM_RF_FREQUENCY 868000000UL
-  #else
-    // Default to EU868 if no explicit region selected
-    #define LORA_COMM_RF_FREQUENCY 868000000UL
-  #endif
-#endif
 
 #ifndef LORA_COMM_TX_POWER_DBM
 #define LORA_COMM_TX_POWER_DBM 14
@@ -83,6 +77,13 @@
 #define LORA_COMM_MAX_RETRIES 4
 #endif
 
+// A DATA frame repeating a peer's last msgId within this window is a retry
+// whose ACK was lost; it is ACKed again but not delivered twice. Bounded so a
+// rebooted peer restarting its msgIds is not mistaken for a duplicate.
+#ifndef LORA_COMM_DEDUP_WINDOW_MS
+#define LORA_COMM_DEDUP_WINDOW_MS (LORA_COMM_ACK_TIMEOUT_MS * (LORA_COMM_MAX_RETRIES + 1))
+#endif
+
 #ifndef LORA_COMM_TX_GUARD_MS
 #define LORA_COMM_TX_GUARD_MS 8000  // Faster recovery if TX completion IRQ is missed
 #endif
@@ -123,6 +124,45 @@ class LoRaComm {
     uint8_t peerId;
     uint32_t lastSeenMs;
     bool connected;
+    int16_t lastRssiDbm;
+    int8_t lastSnrDb;
+    uint32_t rxFrames;
+    uint32_t duplicates;
+    uint16_t lastDataMsgId;
+    uint32_t lastDataMs;
+  };
+
+  // Radio parameters applied on (re)initialization. Defaults come from the
+  // LORA_COMM_* macros; applications may override them at runtime.
+  struct RadioSettings {
+    uint32_t frequencyHz = LORA_COMM_RF_FREQUENCY;
+    int8_t txPowerDbm = LORA_COMM_TX_POWER_DBM;
+    uint8_t bandwidth = LORA_COMM_BANDWIDTH;             // 0=125kHz, 1=250kHz, 2=500kHz
+    uint8_t spreadingFactor = LORA_COMM_SPREADING_FACTOR; // 7-12
+    uint8_t codingRate = LORA_COMM_CODING_RATE;           // 1=4/5 .. 4=4/8
+    uint16_t preambleLength = LORA_COMM_PREAMBLE_LEN;
+  };
+
+  // Cumulative link counters since boot. Unlike the periodic stats these are
+  // never reset, so they are safe to show on a status screen.
+  struct LinkStats {
+    uint32_t txFrames = 0;
+    uint32_t rxFrames = 0;
+    int16_t lastRssiDbm = INT16_MIN; // INT16_MIN until the first frame is heard
+    int8_t lastSnrDb = 0;
+    uint32_t lastRxMs = 0;
+    uint32_t radioResets = 0;        // recoveries from a stuck TX or an unresponsive radio
+    uint32_t silenceResets = 0;      // reinits after hearing nothing for the RX silence timeout
+    uint32_t crcErrors = 0;          // frames heard but corrupted, usually two senders colliding
+  };
+
+  // Transmit airtime against the configured duty-cycle budget (refreshed in tick)
+  struct DutyCycleStatus {
+    uint16_t limitPermille = 0; // 0 = unlimited
+    uint32_t usedMs = 0;
+    uint32_t budgetMs = 0;
+    bool throttled = false;     // a TX was held back and none has gone out since
+    uint32_t droppedAcks = 0;
   };
 
   LoRaComm()
@@ -173,9 +213,116 @@ class LoRaComm {
   void setLogLevel(uint8_t level /*Logger::Level*/) { logLevel = level; }
   void setPeerTimeout(uint32_t timeoutMs) { peerTimeoutMs = timeoutMs; }
   void setMasterNodeId(uint8_t masterId) { masterNodeId = masterId; }
+  // Reinitialize the radio if no frame is heard for this long (0 disables).
+  // Meant for nodes that expect regular traffic, e.g. a master with remotes.
+  void setRxSilenceTimeout(uint32_t timeoutMs) { rxSilenceTimeoutMs = timeoutMs; }
 
   ConnectionState getConnectionState() const { return connectionState; }
 
+  // Set radio parameters. Before begin() they are used for the initial
+  // configuration; afterwards the radio is reconfigured on the next tick()
+  // that finds it out of TX.
+  void setRadioSettings(const RadioSettings &settings) {
+    radioSettings = settings;
+    radioReconfigPending = initialized;
+  }
+  const RadioSettings &getRadioSettings() const { return radioSettings; }
+  const LinkStats &getLinkStats() const { return linkStats; }
+
+  void setDutyCycleLimit(uint16_t limitPermille) {
+    dutyCycle.configure(limitPermille);
+    dutyCycleStatus = DutyCycleStatus();
+    dutyCycleStatus.limitPermille = limitPermille;
+    dutyCycleStatus.budgetMs = dutyCycle.getBudgetMs();
+  }
+  const DutyCycleStatus &getDutyCycleStatus() const { return dutyCycleStatus; }
+
+  // Unmodulated carrier on the configured frequency and power, for bench
+  // measurements. The airtime is charged to the duty-cycle budget and the
+  // tone is refused if it doesn't fit. The radio ends it on its own timer;
+  // until then nothing else is sent, and afterwards it goes back to RX.
+  bool startTestTone(uint32_t nowMs, uint16_t seconds) {
+    if (!initialized || !radioResponding || radioState == State::Tx || seconds == 0) return false;
+    const uint32_t airtimeMs = (uint32_t)seconds * 1000;
+    if (dutyCycle.isEnabled() && !dutyCycle.canTransmit(nowMs, airtimeMs)) {
+      Logger::printf(Logger::Level::Warn, "lora", "Test tone refused: %lums over the duty-cycle budget (%lu/%lums used)",
+                     (unsigned long)airtimeMs, (unsigned long)dutyCycle.getUsedMs(nowMs),
+                     (unsigned long)dutyCycle.getBudgetMs());
+      return false;
+    }
+    Radio.Standby();
+    Radio.SetTxContinuousWave(radioSettings.frequencyHz, radioSettings.txPowerDbm, seconds);
+    radioState = State::Tx;
+    testToneActive = true;
+    lastRadioActivityMs = nowMs + airtimeMs; // The TX watchdog counts from the tone's end
+    if (dutyCycle.isEnabled()) {
+      dutyCycle.record(nowMs, airtimeMs);
+    }
+    return true;
+  }
+
+  // Link encryption. With a key set every frame is sealed and unsealed frames
+  // are dropped; without one, sealed frames are dropped. All nodes must agree.
+  // Pass nullptr to disable.
+  bool setEncryptionKey(const uint8_t *key) { return crypto.setKey(key); }
+  uint8_t getMaxAppPayload() const { return maxAppPayload(); }
+  bool isEncryptionEnabled() const { return crypto.isEnabled(); }
+
+  // Next frame counter to send. It must never go backwards for a given key,
+  // so applications persist it (in blocks) and restore it before begin().
+  void setTxFrameCounter(uint32_t next) { txFrameCounter = next; }
+  uint32_t getTxFrameCounter() const { return txFrameCounter; }
+
+  // Highest authenticated frame counter per sender, used for replay checks.
+  // Kept per source id rather than in the peer table so eviction doesn't
+  // forget it. Applications seed these from flash before begin() and save
+  // whatever takeUnsavedRxFrameCounter() hands back. To spare the flash, a
+  // sender's counter is handed back only when it is new or has crossed a
+  // multiple of kRxFrameCounterSaveStep. A seeded counter counts as seen
+  // along with everything below it. RX runs in another task than the
+  // application, so all of these take rxFrameCounterLock.
+  void setRxFrameCounter(uint8_t src, uint32_t highest) {
+    portENTER_CRITICAL(&rxFrameCounterLock);
+    rxFrameCounters[src] = highest;
+    rxFrameWindows[src] = ~(uint64_t)0;
+    rxFrameCounterKnown[src >> 3] |= (uint8_t)(1u << (src & 7));
+    portEXIT_CRITICAL(&rxFrameCounterLock);
+  }
+  bool takeUnsavedRxFrameCounter(uint8_t &src, uint32_t &highest) {
+    bool found = false;
+    portENTER_CRITICAL(&rxFrameCounterLock);
+    for (size_t i = 0; i < sizeof(rxFrameCounterUnsaved) && !found; i++) {
+      if (rxFrameCounterUnsaved[i] == 0) continue;
+      for (uint8_t bit = 0; bit < 8; bit++) {
+        if ((rxFrameCounterUnsaved[i] & (1u << bit)) == 0) continue;
+        rxFrameCounterUnsaved[i] &= (uint8_t)~(1u << bit);
+        src = (uint8_t)(i * 8 + bit);
+        highest = rxFrameCounters[src];
+        found = true;
+        break;
+      }
+    }
+    portEXIT_CRITICAL(&rxFrameCounterLock);
+    return found;
+  }
+  // Forget every sender's counter, e.g. after a key change restarts them
+  void clearRxFrameCounters() {
+    portENTER_CRITICAL(&rxFrameCounterLock);
+    memset(rxFrameCounterKnown, 0, sizeof(rxFrameCounterKnown));
+    memset(rxFrameCounterUnsaved, 0, sizeof(rxFrameCounterUnsaved));
+    portEXIT_CRITICAL(&rxFrameCounterLock);
+  }
+  bool getRxFrameCounter(uint8_t src, uint32_t &highest) const {
+    bool known = false;
+    portENTER_CRITICAL(&rxFrameCounterLock);
+    if ((rxFrameCounterKnown[src >> 3] & (1u << (src & 7))) != 0) {
+      highest = rxFrameCounters[src];
+      known = true;
+    }
+    portEXIT_CRITICAL(&rxFrameCounterLock);
+    return known;
+  }
+
   bool sendData(uint8_t destId, const uint8_t *payload, uint8_t length, bool requireAck = true) {
     if (length > maxAppPayload()) return false;
     // Reserve one slot for housekeeping (e.g., PING) to preserve presence
@@ -194,15 +341,60 @@ class LoRaComm {
     m.requireAck = requireAck;
     m.attempts = 0;
     m.nextAttemptMs = 0;
+    m.hasDeadline = false;
+    m.deadlineMs = 0;
     const uint8_t flags = requireAck ? kFlagRequireAck : 0;
     m.length = buildFrame(m.buf, FrameType::Data, selfId, destId, m.msgId, payload, length, flags);
     Logger::printf(Logger::Level::Debug, "lora", "ENQ DATA to=%u msgId=%u obx=%u", destId, m.msgId, outboxCount);
+    lastQueuedMsgId = m.msgId;
+    return true;
+  }
+
+  // Unacknowledged message that is only valid for a short time, e.g. one that
+  // carries a delay. It goes out ahead of everything else in the outbox, and
+  // is dropped if it can't be sent within maxAgeMs (busy radio, duty cycle).
+  bool sendDataWithin(uint8_t destId, const uint8_t *payload, uint8_t length, uint32_t maxAgeMs) {
+    if (!sendData(destId, payload, length, false)) return false;
+    OutMsg &m = outbox[outboxCount - 1];
+    m.hasDeadline = true;
+    m.deadlineMs = lastNowMs + maxAgeMs;
     return true;
   }
+
+  // Message ID given to the last successful sendData(), to match its ACK or drop callback
+  uint16_t getLastQueuedMessageId() const { return lastQueuedMsgId; }
+  // False while the radio doesn't answer over SPI; tick() keeps retrying init
+  bool isRadioResponding() const { return radioResponding; }
+
+  // Reads back the private sync word that SetPublicNetwork(false) writes;
+  // a match proves the SX126x answers over SPI. Also used by the self-test.
+  static bool probeRadio(uint8_t &msb, uint8_t &lsb) {
+    static constexpr uint16_t kRegSyncWordMsb = 0x0740; // SX126x LoRa sync word registers
+    static constexpr uint16_t kRegSyncWordLsb = 0x0741;
+    msb = Radio.Read(kRegSyncWordMsb);
+    lsb = Radio.Read(kRegSyncWordLsb);
+    return msb == 0x14 && lsb == 0x24;
+  }
   void tick(uint32_t nowMs) {
     lastNowMs = nowMs;
     // Radio.IrqProcess(); // This should be called from the main application loop/task
 
+    // A radio that didn't answer at init is retried with backoff. Until it
+    // does, nothing is sent, but peer, connection and outbox bookkeeping go on.
+    if (initialized && !radioResponding && (int32_t)(nowMs - nextRadioProbeMs) >= 0) {
+      reinitializeRadio();
+      if (!radioResponding) {
+        radioProbeBackoffMs = radioProbeBackoffMs * 2 > kRadioProbeMaxMs ? kRadioProbeMaxMs : radioProbeBackoffMs * 2;
+        nextRadioProbeMs = nowMs + radioProbeBackoffMs;
+        Logger::printf(Logger::Level::Warn, "lora", "Radio still not responding; retry in %lus",
+                       (unsigned long)(radioProbeBackoffMs / 1000));
+      } else {
+        Logger::printf(Logger::Level::Info, "lora", "Radio responding again");
+        lastRadioRecoveryMs = nowMs; // Restart the RX silence watchdog from here
+      }
+    }
+    const bool radioUp = !initialized || radioResponding;
+
     // TX watchdog: recover if TX completion IRQ is missed
     if (radioState == State::Tx) {
       if ((int32_t)(nowMs - lastRadioActivityMs) > (int32_t)LORA_COMM_TX_GUARD_MS) {
@@ -210,7 +402,7 @@ class LoRaComm {
         txStuckConsecutive++;
         if (txStuckConsecutive >= LORA_COMM_TX_STUCK_REINIT_COUNT) {
           Logger::printf(Logger::Level::Warn, "lora", "Reinitializing radio after %u stuck events", (unsigned)txStuckConsecutive);
-          reinitializeRadio();
+          recoverRadio(nowMs);
           txStuckConsecutive = 0;
         }
         // Treat as a soft timeout for the in-flight message
@@ -256,10 +448,37 @@ class LoRaComm {
         }
     }
 
+    // RX watchdog: a receiver that hears nothing for too long may be hung.
+    // Armed once something has been heard, and counted apart from radioResets
+    // because silence is just as likely to mean no remote is in range.
+    if (rxSilenceTimeoutMs != 0 && initialized && radioUp && radioState != State::Tx && linkStats.rxFrames > 0) {
+      const uint32_t quietSince =
+          (int32_t)(linkStats.lastRxMs - lastRadioRecoveryMs) > 0 ? linkStats.lastRxMs : lastRadioRecoveryMs;
+      if ((nowMs - quietSince) > rxSilenceTimeoutMs) {
+        Logger::printf(Logger::Level::Warn, "lora", "Nothing heard for %lus; reinitializing radio",
+                       (unsigned long)((nowMs - quietSince) / 1000));
+        reinitializeRadio();
+        linkStats.silenceResets++;
+        lastRadioRecoveryMs = nowMs;
+      }
+    }
+
+    if (radioReconfigPending && radioUp && radioState != State::Tx) {
+      radioReconfigPending = false;
+      Logger::printf(Logger::Level::Info, "lora", "Applying radio settings: %lu Hz SF%u BW%u CR%u %d dBm",
+                     (unsigned long)radioSettings.frequencyHz, radioSettings.spreadingFactor,
+                     radioSettings.bandwidth, radioSettings.codingRate, (int)radioSettings.txPowerDbm);
+      reinitializeRadio();
+    }
+
     updateConnectionState(nowMs);
 
+    if (dutyCycle.isEnabled()) {
+      dutyCycleStatus.usedMs = dutyCycle.getUsedMs(nowMs);
+    }
+
     // Priority 1: send pending ACK as soon as radio is idle
-    if (pendingAckCount > 0 && radioState != State::Tx) {
+    if (pendingAckCount > 0 && radioUp && radioState != State::Tx) {
       noInterrupts();
       PendingAck ackToSend;
       ackToSend.targetId = pendingAcks[0].targetId;
@@ -273,8 +492,15 @@ class LoRaComm {
       pendingAckCount--;
       interrupts();
 
-      uint8_t frame[8];
+      uint8_t frame[kHeaderSize + kSecureOverhead];
       uint8_t len = buildFrame(frame, FrameType::Ack, selfId, ackToSend.targetId, ackToSend.messageId, nullptr, 0, /*flags=*/0);
+      if (!dutyCycleAllows(nowMs, len)) {
+        // A late ACK is worthless; the sender will retry
+        dutyCycleStatus.droppedAcks++;
+        Logger::printf(Logger::Level::Debug, "lora", "drop ACK to=%u msgId=%u (duty cycle)",
+                       ackToSend.targetId, ackToSend.messageId);
+        return;
+      }
       Logger::printf(Logger::Level::Info, "lora", "TX ACK to=%u msgId=%u (responding to DATA)%s",
                      ackToSend.targetId, ackToSend.messageId,
                      (connectionState == ConnectionState::Connected ? " (connected)" : ""));
@@ -284,9 +510,26 @@ class LoRaComm {
     // Priority 2: transmit or retry queued messages
     // In continuous RX mode, we can transmit even when radioState is Rx
     // The radio will automatically switch to TX when sending
+    // A message held back by the duty cycle is not a stall, and the retry,
+    // expiry and cleanup below must still run while it waits
+    bool throttled = false;
     if (radioState != State::Tx) {
       int idx = selectNextOutboxIndex(nowMs);
-      if (idx >= 0) {
+      if (idx >= 0 && !radioUp) {
+        // Count the attempt as failed so retries, ACK timeouts and drops run
+        // their normal course instead of piling up behind a dead radio
+        OutMsg &m = outbox[idx];
+        m.attempts++;
+        if (m.requireAck) {
+          m.nextAttemptMs = nowMs + LORA_COMM_ACK_TIMEOUT_MS;
+        } else {
+          m.inUse = false;
+          statsDropped++;
+          if (onMsgDroppedCb != nullptr) onMsgDroppedCb(m.msgId, m.attempts);
+        }
+      } else if (idx >= 0 && !dutyCycleAllows(nowMs, outbox[idx].length)) {
+        throttled = true; // Stays queued until the window frees up
+      } else if (idx >= 0) {
         OutMsg &m = outbox[idx];
         m.attempts++;
         if (m.requireAck) {
@@ -311,7 +554,7 @@ class LoRaComm {
 
     // If we reached here, it means we have nothing to send right now.
     // Now check for stall condition.
-    if (outboxCount > 0 && radioState != State::Tx) {
+    if (outboxCount > 0 && radioState != State::Tx && !throttled && radioUp) {
       // We have messages but none are ready to send.
       if (stallDetectStartMs == 0) {
         stallDetectStartMs = nowMs;
@@ -473,21 +716,7 @@ private:
     mode = m;
     selfId = id;
 
-    radioEvents.TxDone = &HandleTxDone;
-    radioEvents.TxTimeout = &HandleTxTimeout;
-    radioEvents.RxDone = &HandleRxDone;
-
-    Radio.Init(&radioEvents);
-    Radio.SetChannel(LORA_COMM_RF_FREQUENCY);
-    Radio.SetTxConfig(MODEM_LORA, LORA_COMM_TX_POWER_DBM, 0, LORA_COMM_BANDWIDTH,
-                      LORA_COMM_SPREADING_FACTOR, LORA_COMM_CODING_RATE,
-                      LORA_COMM_PREAMBLE_LEN, false,
-                      true, 0, 0, LORA_COMM_TX_IQ_INVERT, 3000);
-
-    Radio.SetRxConfig(MODEM_LORA, LORA_COMM_BANDWIDTH, LORA_COMM_SPREADING_FACTOR,
-                      LORA_COMM_CODING_RATE, 0, LORA_COMM_PREAMBLE_LEN,
-                      LORA_COMM_SYMBOL_TIMEOUT, false,
-                      0, true, 0, 0, LORA_COMM_RX_IQ_INVERT, true);
+    configureRadio();
 
     getInstance() = this;
     enterRxMode();
@@ -507,15 +736,25 @@ private:
     bool requireAck;
     uint8_t attempts;
     uint32_t nextAttemptMs;
+    bool hasDeadline;    // sendDataWithin(): send first, drop after deadlineMs
+    uint32_t deadlineMs;
     uint8_t length;
     uint8_t buf[LORA_COMM_MAX_PAYLOAD];
   };
 
   // Framing: [VER=1][TYPE][FLAGS=0][SRC][DST][MSGID_H][MSGID_L][PAYLOAD...]
+  // Secured: [header][FCNT 4, big-endian][encrypted PAYLOAD...][MIC 4]
+  //          header and FCNT are authenticated; nonce = SRC + FCNT
   static constexpr uint8_t kProtocolVersion = 1;
   static constexpr uint8_t kHeaderSize = 7;
+  static constexpr uint8_t kSecureOverhead = 4 + LoRaCrypto::kMicSize;
   // Flags bitfield
   static constexpr uint8_t kFlagRequireAck = 0x01; // when set on DATA, receiver must ACK
+  static constexpr uint8_t kFlagSecure = 0x02;     // frame is sealed with the link key
+  static constexpr uint8_t kReplayWindow = 64; // counters tracked behind the highest per sender
+  static constexpr uint32_t kRxFrameCounterSaveStep = 32;
+  static constexpr uint32_t kRadioProbeMinMs = 1000;
+  static constexpr uint32_t kRadioProbeMaxMs = 60000;
 
   // ACK queue for RX->TX decoupling
   static constexpr uint8_t kMaxPendingAcks = 4;
@@ -553,6 +792,7 @@ private:
   OutMsg outbox[LORA_COMM_MAX_OUTBOX];
   uint8_t outboxCount;
   uint16_t nextMessageId;
+  uint16_t lastQueuedMsgId = 0;
   uint16_t awaitingAckMsgId;
   uint8_t awaitingAckSrcId;
   uint16_t currentTxMsgId = 0;
@@ -561,6 +801,24 @@ private:
   PeerInfo peers[LORA_COMM_MAX_PEERS];
 
   RadioEvents_t radioEvents;
+  RadioSettings radioSettings;
+  bool radioReconfigPending = false;
+  LinkStats linkStats;
+  DutyCycleLimiter dutyCycle;
+  DutyCycleStatus dutyCycleStatus;
+  bool testToneActive = false;
+  LoRaCrypto crypto;
+  uint32_t txFrameCounter = 0;
+  uint32_t rxFrameCounters[256] = {0};
+  uint64_t rxFrameWindows[256] = {0};      // bit n: counter (highest - n) was accepted
+  uint8_t rxFrameCounterKnown[32] = {0};   // bit per source id
+  uint8_t rxFrameCounterUnsaved[32] = {0}; // bit per source id
+  mutable portMUX_TYPE rxFrameCounterLock = portMUX_INITIALIZER_UNLOCKED;
+  uint32_t rxSilenceTimeoutMs = 0;
+  uint32_t lastRadioRecoveryMs = 0;
+  bool radioResponding = true;
+  uint32_t radioProbeBackoffMs = kRadioProbeMinMs;
+  uint32_t nextRadioProbeMs = 0;
   State radioState;
   bool initialized;
 
@@ -588,9 +846,13 @@ private:
   }
 
   static void HandleRxDone(uint8_t *payload, uint16_t size, int16_t rssi, int8_t snr) {
-    (void)snr;
     if (getInstance() == nullptr) return;
-    getInstance()->onRxDone(payload, size, rssi);
+    getInstance()->onRxDone(payload, size, rssi, snr);
+  }
+
+  static void HandleRxError() {
+    if (getInstance() == nullptr) return;
+    getInstance()->onRxError();
   }
 
   void onTxDone() {
@@ -620,6 +882,12 @@ private:
   void onTxTimeout() {
     lastRadioActivityMs = lastNowMs;
     radioState = State::Idle;
+    if (testToneActive) {
+      // A CW tone always ends this way; it isn't a failed frame
+      Logger::printf(Logger::Level::Info, "lora", "Test tone finished");
+      enterRxMode();
+      return;
+    }
     Logger::printf(Logger::Level::Warn, "lora", "TX timeout");
     statsTxTimeouts++;
     const uint16_t timedOutMsgId = currentTxMsgId;
@@ -645,16 +913,22 @@ private:
     enterRxMode();
   }
 
-  void onRxDone(uint8_t *payload, uint16_t size, int16_t rssi) {
+  void onRxDone(uint8_t *payload, uint16_t size, int16_t rssi, int8_t snr) {
     lastRadioActivityMs = lastNowMs;
     lastRssiDbm = rssi;
     Radio.Sleep();
     radioState = State::Idle;
 
-    if (size < kHeaderSize) {
+    // Our frames never exceed LORA_COMM_MAX_PAYLOAD; anything longer is foreign
+    // and must not reach the fixed-size buffers downstream
+    if (size < kHeaderSize || size > LORA_COMM_MAX_PAYLOAD) {
       enterRxMode();
       return;
     }
+    linkStats.rxFrames++;
+    linkStats.lastRssiDbm = rssi;
+    linkStats.lastSnrDb = snr;
+    linkStats.lastRxMs = lastNowMs;
 
     const uint8_t ver = payload[0];
     const FrameType type = (FrameType)payload[1];
@@ -662,8 +936,8 @@ private:
     const uint8_t src = payload[3];
     const uint8_t dst = payload[4];
     const uint16_t msgId = ((uint16_t)payload[5] << 8) | payload[6];
-    const uint8_t appLen = (uint8_t)(size - kHeaderSize);
-    (void)flags; // reserved
+    uint8_t appLen = (uint8_t)(size - kHeaderSize);
+    const uint8_t *appPayload = payload + kHeaderSize;
 
     // Filter destination: accept broadcast (0xFF) or our id
     if (!(dst == 0xFF || dst == selfId)) {
@@ -676,9 +950,59 @@ private:
       return;
     }
 
+    // Secured links: authenticate and decrypt before the frame touches any state
+    const bool secured = (flags & kFlagSecure) != 0;
+    uint32_t frameCounter = 0;
+    bool replayedData = false;
+    uint8_t plain[LORA_COMM_MAX_PAYLOAD];
+    if (secured != crypto.isEnabled()) {
+      LOG_EVERY_MS(5000, { Logger::printf(Logger::Level::Warn, "lora", "drop from=%u: %s frame", src, secured ? "sealed" : "unsealed"); });
+      enterRxMode();
+      return;
+    }
+    if (secured) {
+      if (size < kHeaderSize + kSecureOverhead) {
+        enterRxMode();
+        return;
+      }
+      frameCounter = ((uint32_t)payload[7] << 24) | ((uint32_t)payload[8] << 16) |
+                     ((uint32_t)payload[9] << 8) | (uint32_t)payload[10];
+      appLen = (uint8_t)(size - kHeaderSize - kSecureOverhead);
+      // The cipher writes plaintext before it checks the MIC
+      if (appLen > sizeof(plain)) {
+        enterRxMode();
+        return;
+      }
+      uint8_t nonce[LoRaCrypto::kNonceSize];
+      buildNonce(nonce, src, frameCounter);
+      if (!crypto.open(nonce, payload, kHeaderSize + 4, payload + kHeaderSize + 4, appLen,
+                       payload + kHeaderSize + 4 + appLen, plain)) {
+        LOG_EVERY_MS(5000, { Logger::printf(Logger::Level::Warn, "lora", "drop from=%u: MIC check failed", src); });
+        enterRxMode();
+        return;
+      }
+      appPayload = plain;
+
+      // Replay protection. Frames don't leave in counter order (ACKs and
+      // time-limited frames jump the outbox, and a DATA retry resends its
+      // original sealed bytes), so recent counters are tracked in a window.
+      // A counter seen before is allowed only for DATA, as a retry whose ACK
+      // was lost; it is ACKed again but not delivered.
+      if (!acceptFrameCounter(src, frameCounter, type == FrameType::Data, replayedData)) {
+        Logger::printf(Logger::Level::Warn, "lora", "drop from=%u: replayed fcnt=%lu", src, (unsigned long)frameCounter);
+        enterRxMode();
+        return;
+      }
+    }
+
     // Track peers and basic presence (any valid frame counts)
     // This is important for both master and slave connection state tracking
-    notePeerSeen(src, lastNowMs);
+    PeerInfo *peer = notePeerSeen(src, lastNowMs);
+    if (peer != nullptr) {
+      peer->rxFrames++;
+      peer->lastRssiDbm = rssi;
+      peer->lastSnrDb = snr;
+    }
 
     switch (type) {
       case FrameType::Ack: {
@@ -724,10 +1048,22 @@ private:
           }
         }
         statsRxData++;
+        if (peer != nullptr) {
+          const bool duplicate = replayedData ||
+                                 (peer->lastDataMs != 0 && peer->lastDataMsgId == msgId &&
+                                  (lastNowMs - peer->lastDataMs) < LORA_COMM_DEDUP_WINDOW_MS);
+          peer->lastDataMsgId = msgId;
+          peer->lastDataMs = lastNowMs;
+          if (duplicate) {
+            peer->duplicates++;
+            Logger::printf(Logger::Level::Debug, "lora", "RX DATA dup from=%u msgId=%u; not delivered", src, msgId);
+            break;
+          }
+        }
         Logger::printf(Logger::Level::Info, "lora", "RX DATA from=%u len=%u%s", src, appLen,
                        (flags & kFlagRequireAck) ? " (ACK requested)" : "");
         if (onDataCb != nullptr && appLen > 0) {
-          onDataCb(src, payload + kHeaderSize, appLen);
+          onDataCb(src, appPayload, appLen);
         }
         break;
       }
@@ -738,9 +1074,17 @@ private:
     enterRxMode();
   }
 
+  void onRxError() {
+    lastRadioActivityMs = lastNowMs;
+    linkStats.crcErrors++;
+    Logger::printf(Logger::Level::Debug, "lora", "RX CRC error");
+    enterRxMode();
+  }
+
   void enterRxMode() {
     Radio.Rx(0); // continuous RX
     radioState = State::Rx;
+    testToneActive = false;
     lastRadioActivityMs = millis();
   }
 
@@ -750,13 +1094,15 @@ private:
   }
 
   uint8_t maxAppPayload() const {
-    // Reserve header size
-    if (LORA_COMM_MAX_PAYLOAD <= kHeaderSize) return 0;
-    return (uint8_t)(LORA_COMM_MAX_PAYLOAD - kHeaderSize);
+    // Reserve header size (and counter + MIC on secured links)
+    const uint8_t overhead = kHeaderSize + (crypto.isEnabled() ? kSecureOverhead : 0);
+    if (LORA_COMM_MAX_PAYLOAD <= overhead) return 0;
+    return (uint8_t)(LORA_COMM_MAX_PAYLOAD - overhead);
   }
 
   uint8_t buildFrame(uint8_t *out, FrameType type, uint8_t src, uint8_t dst,
                      uint16_t msgId, const uint8_t *payload, uint8_t length, uint8_t flags = 0) {
+    if (crypto.isEnabled()) flags |= kFlagSecure;
     out[0] = kProtocolVersion;
     out[1] = (uint8_t)type;
     out[2] = flags; // flags
@@ -764,10 +1110,34 @@ private:
     out[4] = dst;
     out[5] = (uint8_t)((msgId >> 8) & 0xFF);
     out[6] = (uint8_t)(msgId & 0xFF);
-    if (payload != nullptr && length > 0) {
-      memcpy(out + kHeaderSize, payload, length);
+    if (!crypto.isEnabled()) {
+      if (payload != nullptr && length > 0) {
+        memcpy(out + kHeaderSize, payload, length);
+      }
+      return (uint8_t)(kHeaderSize + length);
     }
-    return (uint8_t)(kHeaderSize + length);
+
+    // Sealed once at build time, so retries resend identical bytes
+    const uint32_t fcnt = txFrameCounter++;
+    out[7] = (uint8_t)(fcnt >> 24);
+    out[8] = (uint8_t)(fcnt >> 16);
+    out[9] = (uint8_t)(fcnt >> 8);
+    out[10] = (uint8_t)fcnt;
+    uint8_t nonce[LoRaCrypto::kNonceSize];
+    buildNonce(nonce, src, fcnt);
+    static const uint8_t kEmpty[1] = {0};
+    crypto.seal(nonce, out, kHeaderSize + 4, payload != nullptr ? payload : kEmpty, length,
+                out + kHeaderSize + 4, out + kHeaderSize + 4 + length);
+    return (uint8_t)(kHeaderSize + kSecureOverhead + length);
+  }
+
+  static void buildNonce(uint8_t *nonce, uint8_t src, uint32_t frameCounter) {
+    memset(nonce, 0, LoRaCrypto::kNonceSize);
+    nonce[0] = src;
+    nonce[1] = (uint8_t)(frameCounter >> 24);
+    nonce[2] = (uint8_t)(frameCounter >> 16);
+    nonce[3] = (uint8_t)(frameCounter >> 8);
+    nonce[4] = (uint8_t)frameCounter;
   }
 
   void sendFrame(uint8_t *frame, uint8_t length) {
@@ -779,9 +1149,18 @@ private:
     lastRadioActivityMs = millis();
     Radio.Send(frame, length);
     radioState = State::Tx;
+    linkStats.txFrames++;
+    if (dutyCycle.isEnabled()) {
+      dutyCycle.record(lastNowMs, frameAirtimeMs(length));
+    }
   }
 
   int selectNextOutboxIndex(uint32_t nowMs) {
+    // 0) Time-limited messages go first; they are worthless once late
+    for (int i = 0; i < (int)outboxCount; i++) {
+      const OutMsg &m = outbox[i];
+      if (m.inUse && m.hasDeadline && m.attempts == 0) return i;
+    }
     // 1) Prefer retries that are due
     int bestIdx = -1;
     uint32_t bestDue = 0;
@@ -835,28 +1214,43 @@ private:
         if (onMsgDroppedCb != nullptr) onMsgDroppedCb(m.msgId, m.attempts);
         continue;
       }
+      if (m.hasDeadline && m.attempts == 0 && (int32_t)(nowMs - m.deadlineMs) > 0) {
+        statsDropped++;
+        Logger::printf(Logger::Level::Info, "lora", "drop (late) msgId=%u to=%u", m.msgId, m.destId);
+        if (onMsgDroppedCb != nullptr) onMsgDroppedCb(m.msgId, m.attempts);
+        continue;
+      }
       tmp[n++] = m;
     }
     memcpy(outbox, tmp, sizeof(OutMsg) * n);
     outboxCount = n;
   }
 
-  void notePeerSeen(uint8_t peerId, uint32_t nowMs) {
-    if (peerId == 0) return; // 0 reserved
+  const PeerInfo *findPeer(uint8_t peerId) const {
+    if (peerId == 0) return nullptr;
+    for (size_t i = 0; i < LORA_COMM_MAX_PEERS; i++) {
+      if (peers[i].peerId == peerId) return &peers[i];
+    }
+    return nullptr;
+  }
+
+  PeerInfo *notePeerSeen(uint8_t peerId, uint32_t nowMs) {
+    if (peerId == 0) return nullptr; // 0 reserved
     for (size_t i = 0; i < LORA_COMM_MAX_PEERS; i++) {
       if (peers[i].peerId == peerId) {
         peers[i].lastSeenMs = nowMs;
         // Let the tick handler manage the 'connected' state based on timeout.
-        return;
+        return &peers[i];
       }
     }
     // Insert into empty slot
     for (size_t i = 0; i < LORA_COMM_MAX_PEERS; i++) {
       if (peers[i].peerId == 0) {
+        memset(&peers[i], 0, sizeof(PeerInfo));
         peers[i].peerId = peerId;
         peers[i].lastSeenMs = nowMs;
         // The connected flag will be set by the tick handler on its next run.
-        return;
+        return &peers[i];
       }
     }
     // No slot: overwrite the stalest
@@ -865,28 +1259,117 @@ private:
     for (size_t i = 1; i < LORA_COMM_MAX_PEERS; i++) {
       if (peers[i].lastSeenMs < oldest) { oldest = peers[i].lastSeenMs; idx = i; }
     }
+    memset(&peers[idx], 0, sizeof(PeerInfo));
     peers[idx].peerId = peerId;
     peers[idx].lastSeenMs = nowMs;
     // The connected flag will be set by the tick handler on its next run.
+    return &peers[idx];
   }
 
-  void reinitializeRadio() {
-    Radio.Sleep();
+  void configureRadio() {
     radioEvents.TxDone = &HandleTxDone;
     radioEvents.TxTimeout = &HandleTxTimeout;
     radioEvents.RxDone = &HandleRxDone;
+    radioEvents.RxError = &HandleRxError;
 
     Radio.Init(&radioEvents);
-    Radio.SetChannel(LORA_COMM_RF_FREQUENCY);
-    Radio.SetTxConfig(MODEM_LORA, LORA_COMM_TX_POWER_DBM, 0, LORA_COMM_BANDWIDTH,
-                      LORA_COMM_SPREADING_FACTOR, LORA_COMM_CODING_RATE,
-                      LORA_COMM_PREAMBLE_LEN, false,
+    Radio.SetPublicNetwork(false);
+    Radio.SetChannel(radioSettings.frequencyHz);
+    Radio.SetTxConfig(MODEM_LORA, radioSettings.txPowerDbm, 0, radioSettings.bandwidth,
+                      radioSettings.spreadingFactor, radioSettings.codingRate,
+                      radioSettings.preambleLength, false,
                       true, 0, 0, LORA_COMM_TX_IQ_INVERT, 3000);
 
-    Radio.SetRxConfig(MODEM_LORA, LORA_COMM_BANDWIDTH, LORA_COMM_SPREADING_FACTOR,
-                      LORA_COMM_CODING_RATE, 0, LORA_COMM_PREAMBLE_LEN,
+    Radio.SetRxConfig(MODEM_LORA, radioSettings.bandwidth, radioSettings.spreadingFactor,
+                      radioSettings.codingRate, 0, radioSettings.preambleLength,
                       LORA_COMM_SYMBOL_TIMEOUT, false,
                       0, true, 0, 0, LORA_COMM_RX_IQ_INVERT, true);
+
+    // Reading back the sync word proves the SX126x answers over SPI
+    uint8_t msb = 0;
+    uint8_t lsb = 0;
+    const bool responding = probeRadio(msb, lsb);
+    if (!responding && radioResponding) {
+      Logger::printf(Logger::Level::Error, "lora", "Radio not responding over SPI");
+      radioProbeBackoffMs = kRadioProbeMinMs;
+      nextRadioProbeMs = lastNowMs + radioProbeBackoffMs;
+    }
+    radioResponding = responding;
+  }
+
+  // Checks a sender's frame counter against its replay window and records it.
+  // Returns false for a counter that is too old or, unless isData, already seen.
+  bool acceptFrameCounter(uint8_t src, uint32_t counter, bool isData, bool &repeated) {
+    portENTER_CRITICAL(&rxFrameCounterLock);
+    const bool accepted = recordFrameCounter(src, counter, isData, repeated);
+    portEXIT_CRITICAL(&rxFrameCounterLock);
+    return accepted;
+  }
+
+  bool recordFrameCounter(uint8_t src, uint32_t counter, bool isData, bool &repeated) {
+    repeated = false;
+    uint32_t &highest = rxFrameCounters[src];
+    uint64_t &seen = rxFrameWindows[src];
+    if ((rxFrameCounterKnown[src >> 3] & (1u << (src & 7))) == 0) {
+      highest = counter;
+      seen = 1;
+      rxFrameCounterKnown[src >> 3] |= (uint8_t)(1u << (src & 7));
+      rxFrameCounterUnsaved[src >> 3] |= (uint8_t)(1u << (src & 7));
+      return true;
+    }
+    if (counter > highest) {
+      const uint32_t shift = counter - highest;
+      seen = shift >= kReplayWindow ? 1 : (seen << shift) | 1;
+      if (counter / kRxFrameCounterSaveStep != highest / kRxFrameCounterSaveStep) {
+        rxFrameCounterUnsaved[src >> 3] |= (uint8_t)(1u << (src & 7));
+      }
+      highest = counter;
+      return true;
+    }
+    const uint32_t age = highest - counter;
+    if (age >= kReplayWindow) return false;
+    const uint64_t bit = (uint64_t)1 << age;
+    if ((seen & bit) != 0) {
+      repeated = true;
+      return isData;
+    }
+    seen |= bit;
+    return true;
+  }
+
+  uint32_t frameAirtimeMs(uint8_t length) const {
+    return DutyCycleLimiter::timeOnAirMs(radioSettings.spreadingFactor, radioSettings.bandwidth,
+                                         radioSettings.codingRate, radioSettings.preambleLength, length);
+  }
+
+  // Checks the budget for a frame and tracks throttle state transitions
+  bool dutyCycleAllows(uint32_t nowMs, uint8_t length) {
+    if (!dutyCycle.isEnabled()) return true;
+    if (dutyCycle.canTransmit(nowMs, frameAirtimeMs(length))) {
+      if (dutyCycleStatus.throttled) {
+        dutyCycleStatus.throttled = false;
+        Logger::printf(Logger::Level::Info, "lora", "Duty cycle budget available again (%lu/%lu ms)",
+                       (unsigned long)dutyCycleStatus.usedMs, (unsigned long)dutyCycleStatus.budgetMs);
+      }
+      return true;
+    }
+    if (!dutyCycleStatus.throttled) {
+      dutyCycleStatus.throttled = true;
+      Logger::printf(Logger::Level::Warn, "lora", "Duty cycle limit reached (%lu/%lu ms); holding TX",
+                     (unsigned long)dutyCycleStatus.usedMs, (unsigned long)dutyCycleStatus.budgetMs);
+    }
+    return false;
+  }
+
+  void recoverRadio(uint32_t nowMs) {
+    reinitializeRadio();
+    linkStats.radioResets++;
+    lastRadioRecoveryMs = nowMs;
+  }
+
+  void reinitializeRadio() {
+    Radio.Sleep();
+    configureRadio();
     delay(5);
     enterRxMode();
   }
@@ -0,0 +1,49 @@
+#pragma once
+
+#include <stdint.h>
+#include <string.h>
+#include "mbedtls/ccm.h" // Bundled with the ESP32 Arduino core
+
+// AES-128-CCM for LoRa frames: the payload is encrypted, the frame header is
+// authenticated, and a short MIC is appended. Nonces must never repeat for a
+// given key, so callers derive them from a monotonic per-sender counter.
+class LoRaCrypto {
+public:
+  static constexpr uint8_t kKeySize = 16;
+  static constexpr uint8_t kMicSize = 4;
+  static constexpr uint8_t kNonceSize = 13;
+
+  LoRaCrypto() { mbedtls_ccm_init(&ctx); }
+  ~LoRaCrypto() { mbedtls_ccm_free(&ctx); }
+  LoRaCrypto(const LoRaCrypto &) = delete;
+  LoRaCrypto &operator=(const LoRaCrypto &) = delete;
+
+  // nullptr disables encryption
+  bool setKey(const uint8_t *key) {
+    enabled = false;
+    if (key == nullptr) return true;
+    enabled = mbedtls_ccm_setkey(&ctx, MBEDTLS_CIPHER_ID_AES, key, kKeySize * 8) == 0;
+    return enabled;
+  }
+
+  bool isEnabled() const { return enabled; }
+
+  bool seal(const uint8_t *nonce, const uint8_t *aad, size_t aadLength,
+            const uint8_t *plain, size_t length, uint8_t *cipher, uint8_t *mic) {
+    if (!enabled) return false;
+    return mbedtls_ccm_encrypt_and_tag(&ctx, length, nonce, kNonceSize, aad, aadLength,
+                                       plain, cipher, mic, kMicSize) == 0;
+  }
+
+  // Returns false (and leaves plain unspecified) if the MIC does not verify
+  bool open(const uint8_t *nonce, const uint8_t *aad, size_t aadLength,
+            const uint8_t *cipher, size_t length, const uint8_t *mic, uint8_t *plain) {
+    if (!enabled) return false;
+    return mbedtls_ccm_auth_decrypt(&ctx, length, nonce, kNonceSize, aad, aadLength,
+                                    cipher, plain, mic, kMicSize) == 0;
+  }
+
+private:
+  mbedtls_ccm_context ctx;
+  bool enabled = false;
+};
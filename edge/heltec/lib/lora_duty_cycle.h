@@ -0,0 +1,95 @@
+#pragma once
+
+#include <stdint.h>
+
+// Transmit airtime accounting for regulatory duty-cycle limits (e.g. 1% in
+// the common EU868 sub-bands). Airtime is summed over a sliding window made
+// of fixed-size buckets; a transmission is allowed only if it still fits in
+// the window's budget.
+class DutyCycleLimiter {
+public:
+  static constexpr uint8_t kBuckets = 60;
+  static constexpr uint32_t kDefaultWindowMs = 3600000UL; // ETSI measures over one hour
+
+  // limitPermille is the allowed airtime in 1/1000 of the window (10 = 1%); 0 disables
+  void configure(uint16_t limitPermille, uint32_t windowMs = kDefaultWindowMs) {
+    limit = limitPermille;
+    bucketMs = windowMs / kBuckets;
+    if (bucketMs == 0) bucketMs = 1;
+    clear();
+  }
+
+  bool isEnabled() const { return limit != 0; }
+  uint16_t getLimitPermille() const { return limit; }
+
+  uint32_t getBudgetMs() const {
+    return (uint32_t)((uint64_t)bucketMs * kBuckets * limit / 1000);
+  }
+
+  uint32_t getUsedMs(uint32_t nowMs) {
+    expire(nowMs);
+    uint32_t used = 0;
+    for (uint8_t i = 0; i < kBuckets; i++) used += buckets[i];
+    return used;
+  }
+
+  bool canTransmit(uint32_t nowMs, uint32_t airtimeMs) {
+    if (!isEnabled()) return true;
+    return getUsedMs(nowMs) + airtimeMs <= getBudgetMs();
+  }
+
+  void record(uint32_t nowMs, uint32_t airtimeMs) {
+    expire(nowMs);
+    buckets[(nowMs / bucketMs) % kBuckets] += airtimeMs;
+  }
+
+  // LoRa time-on-air (Semtech AN1200.13) for explicit header with CRC on.
+  // bandwidth: 0=125kHz, 1=250kHz, 2=500kHz; codingRate: 1=4/5 .. 4=4/8.
+  static uint32_t timeOnAirMs(uint8_t spreadingFactor, uint8_t bandwidth, uint8_t codingRate,
+                              uint16_t preambleLength, uint8_t payloadLength) {
+    static const uint32_t kBandwidthHz[] = {125000UL, 250000UL, 500000UL};
+    const uint32_t bwHz = kBandwidthHz[bandwidth <= 2 ? bandwidth : 0];
+    const uint32_t symbolUs = (uint32_t)(((uint64_t)1 << spreadingFactor) * 1000000ULL / bwHz);
+    const int32_t lowDataRate = symbolUs >= 16000 ? 1 : 0;
+
+    const int32_t numerator = 8 * (int32_t)payloadLength - 4 * (int32_t)spreadingFactor + 28 + 16;
+    const int32_t denominator = 4 * ((int32_t)spreadingFactor - 2 * lowDataRate);
+    int32_t payloadBlocks = 0;
+    if (numerator > 0) payloadBlocks = (numerator + denominator - 1) / denominator;
+    const uint32_t payloadSymbols = 8 + (uint32_t)payloadBlocks * (codingRate + 4);
+
+    const uint32_t preambleUs = ((uint32_t)preambleLength * 4 + 17) * symbolUs / 4; // n + 4.25 symbols
+    const uint32_t totalUs = preambleUs + payloadSymbols * symbolUs;
+    return (totalUs + 999) / 1000;
+  }
+
+private:
+  void clear() {
+    for (uint8_t i = 0; i < kBuckets; i++) buckets[i] = 0;
+    started = false;
+  }
+
+  // Zero buckets that have slid out of the window since the last call.
+  // A millis() wrap looks like a long gap and simply clears the window.
+  void expire(uint32_t nowMs) {
+    const uint32_t epoch = nowMs / bucketMs;
+    if (!started) {
+      lastEpoch = epoch;
+      started = true;
+      return;
+    }
+    const uint32_t elapsed = epoch - lastEpoch;
+    if (elapsed >= kBuckets) {
+      for (uint8_t i = 0; i < kBuckets; i++) buckets[i] = 0;
+    } else {
+      for (uint32_t e = lastEpoch + 1; e <= epoch; e++) buckets[e % kBuckets] = 0;
+    }
+    lastEpoch = epoch;
+  }
+
+  uint16_t limit = 0;
+  uint32_t bucketMs = kDefaultWindowMs / kBuckets;
+  uint32_t buckets[kBuckets] = {0};
+  uint32_t lastEpoch = 0;
+  bool started = false;
+};
@@ -0,0 +1,23 @@
+#pragma once
+
+// Region selection (override by defining LORA_COMM_RF_FREQUENCY or a region macro before include)
+#ifndef LORA_COMM_RF_FREQUENCY
+  #if defined(LORA_REGION_US915)
+    #define LORA_COMM_RF_FREQUENCY 915000000UL
+  #elif defined(LORA_REGION_EU868)
+    #define LORA_COMM_RF_FREQUENCY 868000000UL
+  #else
+    // Default to EU868 if no explicit region selected
+    #define LORA_COMM_RF_FREQUENCY 868000000UL
+  #endif
+#endif
+
+// Regulatory TX airtime limit in 1/1000 per hour (10 = 1%), 0 = none.
+// EU868 allows 1% in the g1 sub-band; US915 has no duty-cycle rule.
+#ifndef LORA_COMM_DUTY_CYCLE_PERMILLE
+  #if defined(LORA_REGION_US915)
+    #define LORA_COMM_DUTY_CYCLE_PERMILLE 0
+  #else
+    #define LORA_COMM_DUTY_CYCLE_PERMILLE 10
+  #endif
+#endif
@@ -19,6 +19,7 @@ struct MqttPublisherConfig {
     const char* deviceTopic = nullptr; // optional suffix override
     uint8_t qos = 0;
     bool retain = false;
+    const char* statusTopic = nullptr; // optional suffix; retained "online", broker publishes "offline" as last will
     
     // Reliability settings
     uint32_t connectionTimeoutMs = 10000;    // 10 second connection timeout
@@ -135,6 +136,9 @@ public:
                     lastConnectionTime = nowMs;
                     retryAttempts = 0;
                     currentRetryInterval = cfg.retryIntervalMs; // Reset retry interval
+                    if (cfg.statusTopic) {
+                        publishRetained(cfg.statusTopic, "online"); // Replaces the last will
+                    }
                 } else {
                     Serial.printf("[MQTT] SESSION DISCONNECTED\n");
                     connectionState = MqttConnectionState::Disconnected;
@@ -249,11 +253,9 @@ public:
         
         char topic[128];
         if (cfg.deviceTopic && cfg.deviceTopic[0] != '\0') {
-            snprintf(topic, sizeof(topic), "%s/%s", cfg.baseTopic ? cfg.baseTopic : "farm/telemetry", cfg.deviceTopic);
-        } else if (topicSuffix && topicSuffix[0] != '\0') {
-            snprintf(topic, sizeof(topic), "%s/%s", cfg.baseTopic ? cfg.baseTopic : "farm/telemetry", topicSuffix);
+            formatTopic(cfg.deviceTopic, topic, sizeof(topic));
         } else {
-            snprintf(topic, sizeof(topic), "%s", cfg.baseTopic ? cfg.baseTopic : "farm/telemetry");
+            formatTopic(topicSuffix, topic, sizeof(topic));
         }
 
         // Try immediate publish if connected
@@ -299,6 +301,26 @@ public:
         return false;
     }
 
+    // Publish retained state to baseTopic + "/" + topicSuffix, ignoring deviceTopic.
+    // Never queued: state is only worth sending while connected, and callers
+    // re-send it after a reconnect.
+    bool publishRetained(const char* topicSuffix, const char* payload) {
+        if (!cfg.enableMqtt || !payload || !client || !client->connected()) {
+            return false;
+        }
+        char topic[128];
+        formatTopic(topicSuffix, topic, sizeof(topic));
+        bool ok = client->publish(topic, payload, (int)strlen(payload), true, 1);
+        if (ok) {
+            LOGD("MQTT", "Published retained '%s' to %s", payload, topic);
+            statsSuccessfulPublishes++;
+        } else {
+            LOGW("MQTT", "Retained publish failed to %s", topic);
+            statsFailedPublishes++;
+        }
+        return ok;
+    }
+
 private:
     MqttPublisherConfig cfg;
     uint32_t lastConnAttemptMs = 0;
@@ -323,11 +345,27 @@ private:
     uint32_t statsSuccessfulPublishes = 0;
     uint32_t statsFailedPublishes = 0;
 
+    void formatTopic(const char* topicSuffix, char* out, size_t outSize) const {
+        const char* base = cfg.baseTopic ? cfg.baseTopic : "farm/telemetry";
+        if (topicSuffix && topicSuffix[0] != '\0') {
+            snprintf(out, outSize, "%s/%s", base, topicSuffix);
+        } else {
+            snprintf(out, outSize, "%s", base);
+        }
+    }
+
     void reconnect() {
         if (!client) return;
         
         // Use configurable timeout for better reliability
         client->setOptions(cfg.keepAliveMs, true, cfg.connectionTimeoutMs);
+
+        // Broker publishes "offline" for us if the session drops without a clean disconnect
+        if (cfg.statusTopic) {
+            char willTopic[128];
+            formatTopic(cfg.statusTopic, willTopic, sizeof(willTopic));
+            client->setWill(willTopic, "offline", true, 1);
+        }
         
         Serial.printf("[MQTT] Connecting to %s:%u as %s...\n", 
                      cfg.brokerHost, (unsigned)cfg.brokerPort, 
@@ -12,6 +12,10 @@ uint8_t BatteryService::getBatteryPercent() const {
     return batteryHal.getBatteryPercent();
 }
 
+uint16_t BatteryService::getVoltageMilliVolts() const {
+    return batteryHal.getVoltageMilliVolts();
+}
+
 bool BatteryService::isCharging() const {
     return batteryHal.isCharging();
 }
@@ -8,6 +8,7 @@ public:
     virtual ~IBatteryService() = default;
     virtual void update(uint32_t nowMs) = 0;
     virtual uint8_t getBatteryPercent() const = 0;
+    virtual uint16_t getVoltageMilliVolts() const = 0; // 0 when no battery ADC is configured
     virtual bool isCharging() const = 0;
 };
 
@@ -17,6 +18,7 @@ public:
 
     void update(uint32_t nowMs) override;
     uint8_t getBatteryPercent() const override;
+    uint16_t getVoltageMilliVolts() const override;
     bool isCharging() const override;
 
 private:
@@ -0,0 +1,100 @@
+#include "svc_log_stream.h"
+
+LogStreamService* LogStreamService::_instance = nullptr;
+
+LogStreamService::LogStreamService(IWifiHal& wifiHal, const char* topicSuffix, uint16_t maxPerMinute)
+    : _wifiHal(wifiHal), _topicSuffix(topicSuffix), _maxPerMinute(maxPerMinute) {
+}
+
+LogStreamService::~LogStreamService() {
+    if (_instance == this) {
+        Logger::setSink(nullptr, Logger::Level::Error);
+        _instance = nullptr;
+    }
+}
+
+void LogStreamService::begin(Logger::Level minLevel) {
+    _instance = this;
+    Logger::setSink(&LogStreamService::sink, minLevel);
+}
+
+void LogStreamService::sink(Logger::Level level, const char* tag, const char* message) {
+    if (_instance) {
+        _instance->enqueue(level, tag, message);
+    }
+}
+
+void LogStreamService::enqueue(Logger::Level level, const char* tag, const char* message) {
+    if (_publishingTask != nullptr && _publishingTask == xTaskGetCurrentTaskHandle()) return;
+    static const char kLevels[] = {'E', 'W', 'I', 'D', 'V'};
+    const uint8_t levelIndex = static_cast<uint8_t>(level);
+
+    // Format outside the critical section; only the copy runs with interrupts off
+    char line[kLineSize];
+    snprintf(line, sizeof(line), "%c [%s] %s",
+             levelIndex < sizeof(kLevels) ? kLevels[levelIndex] : '?',
+             tag ? tag : "log", message);
+
+    portENTER_CRITICAL(&_lock);
+    if (_count >= kQueueSize) {
+        _dropped++;
+    } else {
+        memcpy(_queue[(_head + _count) % kQueueSize], line, kLineSize);
+        _count++;
+    }
+    portEXIT_CRITICAL(&_lock);
+}
+
+// Only update() removes lines, so the head stays put between peek() and pop()
+bool LogStreamService::peek(char* out) {
+    bool ok = false;
+    portENTER_CRITICAL(&_lock);
+    if (_count > 0) {
+        memcpy(out, _queue[_head], kLineSize);
+        ok = true;
+    }
+    portEXIT_CRITICAL(&_lock);
+    return ok;
+}
+
+void LogStreamService::pop() {
+    portENTER_CRITICAL(&_lock);
+    if (_count > 0) {
+        _head = (_head + 1) % kQueueSize;
+        _count--;
+    }
+    portEXIT_CRITICAL(&_lock);
+}
+
+void LogStreamService::update(uint32_t nowMs) {
+    if (nowMs - _windowStartMs >= 60000) {
+        _windowStartMs = nowMs;
+        _sentInWindow = 0;
+    }
+    if (!_wifiHal.isMqttReady()) return; // Lines wait in the queue; overflow is counted
+
+    _publishingTask = xTaskGetCurrentTaskHandle();
+    char line[kLineSize];
+    while (_sentInWindow < _maxPerMinute) {
+        portENTER_CRITICAL(&_lock);
+        const uint32_t dropped = _dropped;
+        portEXIT_CRITICAL(&_lock);
+
+        const bool isDropReport = dropped != _droppedReported;
+        if (isDropReport) {
+            snprintf(line, sizeof(line), "W [Log] %lu lines dropped", (unsigned long)(dropped - _droppedReported));
+        } else if (!peek(line)) {
+            break;
+        }
+        if (!_wifiHal.publishMqtt(_topicSuffix, reinterpret_cast<const uint8_t*>(line), (uint8_t)strlen(line))) {
+            break; // MQTT queue full; the line stays queued for the next pass
+        }
+        if (isDropReport) {
+            _droppedReported = dropped;
+        } else {
+            pop();
+        }
+        _sentInWindow++;
+    }
+    _publishingTask = nullptr;
+}
@@ -0,0 +1,60 @@
+#pragma once
+
+#include <Arduino.h>
+#include <stdint.h>
+#include "core_logger.h"
+#include "hal_wifi.h"
+
+// Streams log lines to MQTT so a remote relay can be diagnosed without USB.
+// - A Logger sink copies lines at or above the chosen level into a small
+//   queue; this is safe from any task
+// - update() publishes them as "W [Tag] message" to <baseTopic>/<topicSuffix>,
+//   at most maxPerMinute per minute
+// - Lines lost to a full queue are counted and reported as one line; a line
+//   MQTT won't take stays queued and is retried on the next update()
+// Only one instance can be active at a time; the Logger has a single sink.
+class ILogStreamService {
+public:
+    virtual ~ILogStreamService() = default;
+    virtual void begin(Logger::Level minLevel) = 0;
+    virtual void update(uint32_t nowMs) = 0;
+    virtual uint32_t getDroppedCount() const = 0;
+};
+
+class LogStreamService : public ILogStreamService {
+public:
+    LogStreamService(IWifiHal& wifiHal, const char* topicSuffix, uint16_t maxPerMinute);
+    ~LogStreamService() override;
+
+    void begin(Logger::Level minLevel) override;
+    void update(uint32_t nowMs) override;
+    uint32_t getDroppedCount() const override { return _dropped; }
+
+private:
+    static constexpr uint8_t kQueueSize = 8;
+    static constexpr uint8_t kLineSize = 128;
+
+    static void sink(Logger::Level level, const char* tag, const char* message);
+    void enqueue(Logger::Level level, const char* tag, const char* message);
+    bool peek(char* out);
+    void pop();
+
+    static LogStreamService* _instance;
+
+    IWifiHal& _wifiHal;
+    const char* _topicSuffix;
+    uint16_t _maxPerMinute;
+
+    char _queue[kQueueSize][kLineSize];
+    uint8_t _head = 0;
+    uint8_t _count = 0;
+    uint32_t _dropped = 0;
+    uint32_t _droppedReported = 0;
+    // update() skips its own MQTT logs, which would feed back into the queue;
+    // other tasks still queue lines while it publishes
+    volatile TaskHandle_t _publishingTask = nullptr;
+    portMUX_TYPE _lock = portMUX_INITIALIZER_UNLOCKED;
+
+    uint32_t _windowStartMs = 0;
+    uint16_t _sentInWindow = 0;
+};
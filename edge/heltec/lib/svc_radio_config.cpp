@@ -0,0 +1,325 @@
+#include "svc_radio_config.h"
+#include "core_logger.h"
+#include <ctype.h>
+#include <stdlib.h>
+
+RadioConfigService::RadioConfigService(ILoRaHal& loraHal, IPersistenceHal& persistence,
+                                       const LoraConfig& defaults, Stream& serial)
+    : _loraHal(loraHal),
+      _persistence(persistence),
+      _defaults(defaults),
+      _serial(serial),
+      _settings(defaultsFromConfig()) {
+}
+
+void RadioConfigService::begin() {
+    load();
+    const char* error = validate(_settings);
+    if (error != nullptr) {
+        LOGW("Radio", "Stored radio settings invalid (%s); using defaults", error);
+        _settings = defaultsFromConfig();
+        _hasOverrides = false;
+    }
+    apply();
+    applyKey();
+    LOGI("Radio", "Radio %lu Hz SF%u BW%u CR%u %d dBm (%s), link %s",
+         (unsigned long)_settings.frequencyHz, _settings.spreadingFactor, _settings.bandwidth,
+         _settings.codingRate, (int)_settings.txPowerDbm, _hasOverrides ? "flash" : "default",
+         _hasKey ? "encrypted" : "plain");
+}
+
+void RadioConfigService::update(uint32_t nowMs) {
+    (void)nowMs;
+    if (_hasKey && _loraHal.getTxFrameCounter() + kFrameCounterBlock / 2 >= _frameCounterReserved) {
+        reserveFrameCounters();
+    }
+    saveRxFrameCounters();
+    while (_serial.available() > 0) {
+        int c = _serial.read();
+        if (c < 0) break;
+        if (c == '\r') continue;
+        if (c == '\n') {
+            if (!_lineOverflow && _lineLength > 0) {
+                _line[_lineLength] = '\0';
+                handleLine(_line);
+            }
+            _lineLength = 0;
+            _lineOverflow = false;
+            continue;
+        }
+        if (_lineLength >= kMaxLineLength) {
+            _lineOverflow = true;
+            continue;
+        }
+        _line[_lineLength++] = (char)c;
+    }
+}
+
+const char* RadioConfigService::validate(const RadioSettings& settings) {
+    // SX1262 tuning range
+    if (settings.frequencyHz < 150000000UL || settings.frequencyHz > 960000000UL) return "freq out of range";
+    if (settings.spreadingFactor < 7 || settings.spreadingFactor > 12) return "sf must be 7-12";
+    if (settings.bandwidth > 2) return "bw must be 0-2";
+    if (settings.codingRate < 1 || settings.codingRate > 4) return "cr must be 1-4";
+    if (settings.txPowerDbm < -9 || settings.txPowerDbm > 22) return "txp must be -9..22";
+    if (settings.preambleLength < 6) return "preamble too short";
+    return nullptr;
+}
+
+ILoRaHal::RadioSettings RadioConfigService::defaultsFromConfig() const {
+    RadioSettings s;
+    s.frequencyHz = _defaults.frequency;
+    s.txPowerDbm = (int8_t)_defaults.txPower;
+    s.bandwidth = _defaults.bandwidth;
+    s.spreadingFactor = _defaults.spreadingFactor;
+    s.codingRate = _defaults.codingRate;
+    s.preambleLength = _defaults.preambleLength;
+    return s;
+}
+
+void RadioConfigService::load() {
+    RadioSettings defaults = defaultsFromConfig();
+    _persistence.begin(kNamespace);
+    _hasKey = parseKey(_persistence.loadString("key", "").c_str(), _key);
+    _frameCounterReserved = _persistence.loadU32("fcnt", 0);
+    _hasOverrides = _persistence.loadU32("custom", 0) != 0;
+    if (_hasOverrides) {
+        _settings.frequencyHz = _persistence.loadU32("freq", defaults.frequencyHz);
+        _settings.txPowerDbm = (int8_t)(int32_t)_persistence.loadU32("txp", (uint32_t)(int32_t)defaults.txPowerDbm);
+        _settings.bandwidth = (uint8_t)_persistence.loadU32("bw", defaults.bandwidth);
+        _settings.spreadingFactor = (uint8_t)_persistence.loadU32("sf", defaults.spreadingFactor);
+        _settings.codingRate = (uint8_t)_persistence.loadU32("cr", defaults.codingRate);
+    } else {
+        _settings = defaults;
+    }
+    _persistence.end();
+}
+
+void RadioConfigService::save() {
+    _persistence.begin(kNamespace);
+    _persistence.saveU32("freq", _settings.frequencyHz);
+    _persistence.saveU32("txp", (uint32_t)(int32_t)_settings.txPowerDbm);
+    _persistence.saveU32("bw", _settings.bandwidth);
+    _persistence.saveU32("sf", _settings.spreadingFactor);
+    _persistence.saveU32("cr", _settings.codingRate);
+    _persistence.saveU32("custom", 1);
+    _persistence.end();
+    _hasOverrides = true;
+}
+
+void RadioConfigService::clearOverrides() {
+    _persistence.begin(kNamespace);
+    _persistence.saveU32("custom", 0);
+    _persistence.end();
+    _hasOverrides = false;
+    _settings = defaultsFromConfig();
+}
+
+void RadioConfigService::apply() {
+    _loraHal.setRadioSettings(_settings);
+}
+
+void RadioConfigService::applyKey() {
+    if (!_hasKey) {
+        _loraHal.setEncryptionKey(nullptr);
+        return;
+    }
+    // Resume from the last reserved block; anything below it may have been used
+    _loraHal.setTxFrameCounter(_frameCounterReserved);
+    reserveFrameCounters();
+    loadRxFrameCounters();
+    if (!_loraHal.setEncryptionKey(_key)) {
+        LOGW("Radio", "Link key rejected; radio stays unencrypted");
+    }
+}
+
+void RadioConfigService::reserveFrameCounters() {
+    _frameCounterReserved = _loraHal.getTxFrameCounter() + kFrameCounterBlock;
+    _persistence.begin(kNamespace);
+    _persistence.saveU32("fcnt", _frameCounterReserved);
+    _persistence.end();
+}
+
+// Keys are "rx<id>" holding the counter plus one, so 0 means never heard
+void RadioConfigService::loadRxFrameCounters() {
+    char key[8];
+    _persistence.begin(kNamespace);
+    for (uint16_t id = 1; id < 0xFF; id++) {
+        snprintf(key, sizeof(key), "rx%u", id);
+        const uint32_t stored = _persistence.loadU32(key, 0);
+        if (stored != 0) _loraHal.setRxFrameCounter((uint8_t)id, stored - 1);
+    }
+    _persistence.end();
+}
+
+void RadioConfigService::saveRxFrameCounters() {
+    uint8_t srcId = 0;
+    uint32_t highest = 0;
+    if (!_loraHal.takeUnsavedRxFrameCounter(srcId, highest)) return;
+    char key[8];
+    _persistence.begin(kNamespace);
+    do {
+        snprintf(key, sizeof(key), "rx%u", srcId);
+        _persistence.saveU32(key, highest + 1);
+    } while (_loraHal.takeUnsavedRxFrameCounter(srcId, highest));
+    _persistence.end();
+}
+
+void RadioConfigService::forgetRxFrameCounters() {
+    char key[8];
+    uint32_t highest = 0;
+    _persistence.begin(kNamespace);
+    for (uint16_t id = 1; id < 0xFF; id++) {
+        if (!_loraHal.getRxFrameCounter((uint8_t)id, highest)) continue;
+        snprintf(key, sizeof(key), "rx%u", id);
+        _persistence.saveU32(key, 0);
+    }
+    _persistence.end();
+    _loraHal.clearRxFrameCounters();
+}
+
+void RadioConfigService::saveKey() {
+    char hex[LoRaCrypto::kKeySize * 2 + 1] = {0};
+    if (_hasKey) {
+        for (uint8_t i = 0; i < LoRaCrypto::kKeySize; i++) {
+            snprintf(hex + i * 2, 3, "%02x", _key[i]);
+        }
+    }
+    _persistence.begin(kNamespace);
+    _persistence.saveString("key", hex);
+    _persistence.end();
+}
+
+bool RadioConfigService::parseKey(const char* hex, uint8_t* out) {
+    if (hex == nullptr || strlen(hex) != LoRaCrypto::kKeySize * 2) return false;
+    for (uint8_t i = 0; i < LoRaCrypto::kKeySize; i++) {
+        const char byteHex[3] = {hex[i * 2], hex[i * 2 + 1], '\0'};
+        if (!isxdigit((unsigned char)byteHex[0]) || !isxdigit((unsigned char)byteHex[1])) return false;
+        out[i] = (uint8_t)strtol(byteHex, nullptr, 16);
+    }
+    return true;
+}
+
+void RadioConfigService::handleLine(char* line) {
+    if (strncmp(line, "CONFIG", 6) != 0 || (line[6] != '\0' && line[6] != ' ')) {
+        return; // Not for us
+    }
+    char* args = line + 6;
+    while (*args == ' ') args++;
+
+    if (*args == '\0') {
+        printSettings("CONFIG");
+        return;
+    }
+
+    if (strcmp(args, "reset") == 0) {
+        clearOverrides();
+        apply();
+        LOGI("Radio", "Radio overrides cleared");
+        printSettings("CONFIG OK");
+        return;
+    }
+
+    RadioSettings candidate = _settings;
+    uint8_t candidateKey[LoRaCrypto::kKeySize];
+    memcpy(candidateKey, _key, sizeof(candidateKey));
+    bool keyChanged = false;
+    const char* error = nullptr;
+    if (!parseAssignments(args, candidate, candidateKey, keyChanged, error) || (error = validate(candidate)) != nullptr) {
+        _serial.printf("CONFIG ERR %s\n", error ? error : "parse error");
+        return;
+    }
+
+    if (keyChanged) {
+        bool anySet = false;
+        for (uint8_t i = 0; i < LoRaCrypto::kKeySize; i++) anySet |= candidateKey[i] != 0;
+        memcpy(_key, candidateKey, sizeof(_key));
+        _hasKey = anySet;
+        saveKey();
+        forgetRxFrameCounters();
+        applyKey();
+        LOGI("Radio", "Link key %s over serial", _hasKey ? "set" : "cleared");
+    }
+    const bool settingsChanged = candidate.frequencyHz != _settings.frequencyHz ||
+                                 candidate.txPowerDbm != _settings.txPowerDbm ||
+                                 candidate.bandwidth != _settings.bandwidth ||
+                                 candidate.spreadingFactor != _settings.spreadingFactor ||
+                                 candidate.codingRate != _settings.codingRate;
+    if (settingsChanged) {
+        _settings = candidate;
+        save();
+        apply();
+        LOGI("Radio", "Radio settings updated over serial");
+    }
+    printSettings("CONFIG OK");
+}
+
+bool RadioConfigService::parseAssignments(char* args, RadioSettings& out, uint8_t* keyOut, bool& keyChanged, const char*& error) {
+    char* savePtr = nullptr;
+    for (char* token = strtok_r(args, " ", &savePtr); token != nullptr; token = strtok_r(nullptr, " ", &savePtr)) {
+        char* eq = strchr(token, '=');
+        if (eq == nullptr || eq[1] == '\0') {
+            error = "expected key=value";
+            return false;
+        }
+        *eq = '\0';
+        const char* key = token;
+        if (strcmp(key, "key") == 0) {
+            if (strcmp(eq + 1, "off") == 0) {
+                memset(keyOut, 0, LoRaCrypto::kKeySize); // All-zero means "off" to the caller
+            } else if (!parseKey(eq + 1, keyOut)) {
+                error = "key must be 32 hex digits or off";
+                return false;
+            } else {
+                bool anySet = false;
+                for (uint8_t i = 0; i < LoRaCrypto::kKeySize; i++) anySet |= keyOut[i] != 0;
+                if (!anySet) {
+                    error = "key must not be all zero";
+                    return false;
+                }
+            }
+            keyChanged = true;
+            continue;
+        }
+        char* end = nullptr;
+        long value = strtol(eq + 1, &end, 10);
+        if (end == nullptr || *end != '\0') {
+            error = "value must be an integer";
+            return false;
+        }
+
+        // Range checks happen in validate(); only guard against truncation here
+        const bool fitsU8 = value >= 0 && value <= 255;
+        if (strcmp(key, "freq") == 0) {
+            if (value < 0) { error = "freq out of range"; return false; }
+            out.frequencyHz = (uint32_t)value;
+        } else if (strcmp(key, "sf") == 0) {
+            if (!fitsU8) { error = "sf must be 7-12"; return false; }
+            out.spreadingFactor = (uint8_t)value;
+        } else if (strcmp(key, "bw") == 0) {
+            if (!fitsU8) { error = "bw must be 0-2"; return false; }
+            out.bandwidth = (uint8_t)value;
+        } else if (strcmp(key, "cr") == 0) {
+            if (!fitsU8) { error = "cr must be 1-4"; return false; }
+            out.codingRate = (uint8_t)value;
+        } else if (strcmp(key, "txp") == 0) {
+            if (value < -128 || value > 127) { error = "txp must be -9..22"; return false; }
+            out.txPowerDbm = (int8_t)value;
+        } else {
+            error = "unknown key";
+            return false;
+        }
+    }
+    return true;
+}
+
+void RadioConfigService::printSettings(const char* prefix) {
+    _serial.printf("%s freq=%lu sf=%u bw=%u cr=%u txp=%d key=%s\n",
+                   prefix,
+                   (unsigned long)_settings.frequencyHz,
+                   _settings.spreadingFactor,
+                   _settings.bandwidth,
+                   _settings.codingRate,
+                   (int)_settings.txPowerDbm,
+                   _hasKey ? "on" : "off");
+}
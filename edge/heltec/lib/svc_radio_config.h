@@ -0,0 +1,86 @@
+#pragma once
+
+#include <Arduino.h>
+#include <stdint.h>
+#include "hal_lora.h"
+#include "hal_persistence.h"
+#include "communication_config.h"
+
+// Runtime LoRa radio settings.
+// - Defaults come from the device's LoraConfig
+// - Overrides are persisted to flash (namespace "radio") and applied at boot
+// - Overrides can be set over the USB serial link with a single text line:
+//     CONFIG                                  -> print current settings
+//     CONFIG freq=915000000 sf=9 bw=0 cr=1 txp=17
+//     CONFIG reset                            -> drop overrides, use LoraConfig
+//     CONFIG key=<32 hex digits> | key=off    -> set or clear the AES-128 link key
+//   Replies are "CONFIG OK ..." or "CONFIG ERR <reason>". The key is never echoed.
+// Relay and remotes must run the same settings (and key) to hear each other.
+// With a key set, the TX frame counter is reserved in flash in blocks so it
+// keeps increasing across reboots. The counter accepted from each sender is
+// saved each time it passes a multiple of 32, so replays of anything older
+// are still refused after a reboot. Changing the key forgets the saved
+// receive counters.
+class IRadioConfigService {
+public:
+    using RadioSettings = ILoRaHal::RadioSettings;
+
+    virtual ~IRadioConfigService() = default;
+    virtual void begin() = 0;
+    virtual void update(uint32_t nowMs) = 0;
+    virtual const RadioSettings& getSettings() const = 0;
+    virtual bool hasOverrides() const = 0;
+    virtual bool hasKey() const = 0;
+};
+
+class RadioConfigService : public IRadioConfigService {
+public:
+    RadioConfigService(ILoRaHal& loraHal, IPersistenceHal& persistence, const LoraConfig& defaults, Stream& serial);
+
+    void begin() override;
+    void update(uint32_t nowMs) override;
+    const RadioSettings& getSettings() const override { return _settings; }
+    bool hasOverrides() const override { return _hasOverrides; }
+    bool hasKey() const override { return _hasKey; }
+
+    // Returns nullptr if the settings are usable, otherwise a short reason.
+    static const char* validate(const RadioSettings& settings);
+
+private:
+    static constexpr const char* kNamespace = "radio";
+    static constexpr uint8_t kMaxLineLength = 96;
+    static constexpr uint32_t kFrameCounterBlock = 1024;
+
+    RadioSettings defaultsFromConfig() const;
+    void load();
+    void save();
+    void clearOverrides();
+    void apply();
+    void applyKey();
+    void reserveFrameCounters();
+    void loadRxFrameCounters();
+    void saveRxFrameCounters();
+    void forgetRxFrameCounters();
+    void saveKey();
+
+    void handleLine(char* line);
+    bool parseAssignments(char* args, RadioSettings& out, uint8_t* keyOut, bool& keyChanged, const char*& error);
+    static bool parseKey(const char* hex, uint8_t* out);
+    void printSettings(const char* prefix);
+
+    ILoRaHal& _loraHal;
+    IPersistenceHal& _persistence;
+    const LoraConfig& _defaults;
+    Stream& _serial;
+
+    RadioSettings _settings;
+    bool _hasOverrides = false;
+
+    uint8_t _key[LoRaCrypto::kKeySize] = {0};
+    bool _hasKey = false;
+    uint32_t _frameCounterReserved = 0; // counters below this are safe to use
+
+    char _line[kMaxLineLength + 1] = {0};
+    uint8_t _lineLength = 0;
+    bool _lineOverflow = false;
+};
@@ -8,11 +8,18 @@ UiService::UiService(IDisplayHal& displayHal)
 
 void UiService::init() {
     _splashStartedMs = millis();
+    _lastActivityMs = _splashStartedMs;
+    _lastShiftMs = _splashStartedMs;
     _state = UIState::Splash;
     drawSplashScreen();
 }
 
 void UiService::tick() {
+    updatePower(millis());
+    if (_power == PowerState::Asleep) {
+        return; // Panel is off; skip drawing and the I2C transfer
+    }
+
     // Clear the display at the beginning of each tick
     _displayHal.clear();
 
@@ -35,6 +42,53 @@ void UiService::tick() {
     _displayHal.display();
 }
 
+void UiService::configurePower(uint32_t dimAfterMs, uint32_t sleepAfterMs, uint32_t shiftIntervalMs) {
+    _dimAfterMs = dimAfterMs;
+    _sleepAfterMs = sleepAfterMs;
+    _shiftIntervalMs = shiftIntervalMs;
+}
+
+bool UiService::wake(uint32_t nowMs) {
+    _lastActivityMs = nowMs;
+    const bool wasIdle = _power != PowerState::On;
+    setPowerState(PowerState::On);
+    return wasIdle;
+}
+
+void UiService::updatePower(uint32_t nowMs) {
+    const uint32_t idleMs = nowMs - _lastActivityMs;
+    if (_sleepAfterMs != 0 && idleMs >= _sleepAfterMs) {
+        setPowerState(PowerState::Asleep);
+    } else if (_dimAfterMs != 0 && idleMs >= _dimAfterMs) {
+        setPowerState(PowerState::Dimmed);
+    }
+
+    if (_shiftIntervalMs != 0 && nowMs - _lastShiftMs >= _shiftIntervalMs) {
+        // Walk a 2x2 square so the image never drifts more than a pixel
+        static const int8_t kShift[4][2] = {{0, 0}, {1, 0}, {1, 1}, {0, 1}};
+        _lastShiftMs = nowMs;
+        _shiftStep = (_shiftStep + 1) % 4;
+        _displayHal.setPixelShift(kShift[_shiftStep][0], kShift[_shiftStep][1]);
+    }
+}
+
+void UiService::setPowerState(PowerState state) {
+    if (state == _power) return;
+    switch (state) {
+        case PowerState::On:
+            _displayHal.setPower(true);
+            _displayHal.setBrightness(FULL_BRIGHTNESS);
+            break;
+        case PowerState::Dimmed:
+            _displayHal.setBrightness(DIM_BRIGHTNESS);
+            break;
+        case PowerState::Asleep:
+            _displayHal.setPower(false);
+            break;
+    }
+    _power = state;
+}
+
 void UiService::drawSplashScreen() {
     // The clear is now handled in tick()
     _displayHal.drawXbm(32, 0, 64, 64, logo_bits);
@@ -16,14 +16,40 @@ public:
     void init();
     void tick();
 
+    // Idle power management; 0 disables a step. The display dims, then turns
+    // off, after that long without wake(). While on, the image shifts by a
+    // pixel every shiftIntervalMs to spread OLED wear.
+    void configurePower(uint32_t dimAfterMs, uint32_t sleepAfterMs, uint32_t shiftIntervalMs);
+    // Restarts the idle timer. Returns true if the display was dimmed or off.
+    bool wake(uint32_t nowMs);
+    bool isAsleep() const { return _power == PowerState::Asleep; }
+
     ScreenLayout& getLayout() { return _screenLayout; }
 
 private:
+    enum class PowerState {
+        On,
+        Dimmed,
+        Asleep
+    };
+
     void drawSplashScreen();
+    void updatePower(uint32_t nowMs);
+    void setPowerState(PowerState state);
 
     IDisplayHal& _displayHal;
     ScreenLayout _screenLayout;
     UIState _state = UIState::Splash;
     uint32_t _splashStartedMs = 0;
     static const uint32_t SPLASH_DURATION_MS = 1200;
+
+    PowerState _power = PowerState::On;
+    uint32_t _dimAfterMs = 0;
+    uint32_t _sleepAfterMs = 0;
+    uint32_t _shiftIntervalMs = 0;
+    uint32_t _lastActivityMs = 0;
+    uint32_t _lastShiftMs = 0;
+    uint8_t _shiftStep = 0;
+    static const uint8_t FULL_BRIGHTNESS = 0xCF; // SSD1306 power-on contrast
+    static const uint8_t DIM_BRIGHTNESS = 8;
 };
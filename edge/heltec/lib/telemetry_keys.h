@@ -13,4 +13,42 @@ namespace TelemetryKeys {
     // System
     constexpr const char* ErrorCount = "ec";      // Cumulative error count (uint32_t)
     constexpr const char* TimeSinceReset = "tsr"; // Time since last daily reset (uint32_t, seconds)
+
+    // Link quality - appended by the relay when forwarding, never sent over LoRa
+    constexpr const char* Rssi = "rssi";          // RSSI of the received frame (int, dBm)
+    constexpr const char* Snr = "snr";            // SNR of the received frame (int, dB)
+
+    // Range test - sent by a remote when PRG is pressed; the relay publishes
+    // these on "remote-{id}/test" instead of the telemetry topic
+    constexpr const char* RangeTest = "test";     // Test frame counter since boot (uint32_t), always the first key
+    constexpr const char* SpreadingFactor = "sf"; // Sender's spreading factor (uint8_t)
+    constexpr const char* TxPower = "txp";        // Sender's TX power (int8_t, dBm)
+
+    // Relay health - published by the relay on its own "relay" topic
+    constexpr const char* Uptime = "up";          // Seconds since boot (uint32_t)
+    constexpr const char* FreeHeap = "heap";      // Free heap (uint32_t, bytes)
+    constexpr const char* MinFreeHeap = "heapmin"; // Lowest free heap since boot (uint32_t, bytes)
+    constexpr const char* MaxHeapBlock = "heapblk"; // Largest allocatable block; falls with fragmentation (uint32_t, bytes)
+    constexpr const char* RxFrames = "rx";        // LoRa frames received since boot (uint32_t)
+    constexpr const char* TxFrames = "tx";        // LoRa frames sent since boot (uint32_t)
+    constexpr const char* CrcErrors = "crc";      // Corrupted frames heard since boot, mostly collisions (uint32_t)
+    constexpr const char* SlotMisses = "sm";      // Telemetry frames outside the sender's uplink slot (uint32_t)
+    constexpr const char* RadioResets = "rr";     // Radio recoveries, persisted (uint32_t)
+    constexpr const char* WatchdogResets = "wdt"; // Watchdog reboots, persisted (uint32_t)
+    constexpr const char* DutyCycleUsed = "dc";   // Airtime used of the hourly budget (uint8_t, percent)
+    constexpr const char* PeerCount = "peers";    // Remotes currently connected (uint8_t)
+    constexpr const char* BatteryMilliVolts = "bv"; // Battery voltage (uint16_t, mV)
+    constexpr const char* Charging = "chg";       // 1 while charging (uint8_t)
+    constexpr const char* BatteryLow = "bl";      // 1 below RelayConfig::lowBatteryPercent (uint8_t)
+    constexpr const char* FaultCode = "fault";    // Current failure class, as blinked on the LED (uint8_t, 0 = none)
+
+    // Relay info - retained on "relay/info", republished on every MQTT connect
+    // (also uses SpreadingFactor and TxPower)
+    constexpr const char* Build = "build";        // Firmware build time (yyyymmdd.hhmmss)
+    constexpr const char* DeviceId = "id";        // LoRa device ID (uint8_t)
+    constexpr const char* Frequency = "freq";     // Radio frequency (uint32_t, Hz)
+    constexpr const char* Bandwidth = "bw";       // 0=125kHz, 1=250kHz, 2=500kHz (uint8_t)
+    constexpr const char* CodingRate = "cr";      // 1=4/5 .. 4=4/8 (uint8_t)
+    constexpr const char* LinkKey = "key";        // 1 if frames are encrypted (uint8_t)
+    constexpr const char* NodeCount = "nodes";    // Remotes the relay keeps state for, kept across reboots (uint8_t)
 }
@@ -0,0 +1,53 @@
+#pragma once
+
+#include "ui_element.h"
+#include <vector>
+
+// Shows one child element at a time. Pages advance on their own every
+// rotateIntervalMs (0 disables rotation) or immediately via next().
+class PagedElement : public UIElement {
+public:
+    explicit PagedElement(uint32_t rotateIntervalMs = 4000) : _rotateIntervalMs(rotateIntervalMs) {}
+
+    void addPage(UIElement* page) {
+        _pages.push_back(page);
+    }
+
+    void next(uint32_t nowMs) {
+        if (_pages.empty()) return;
+        _current = (uint8_t)((_current + 1) % _pages.size());
+        _lastChangeMs = nowMs; // A manual flip gets a full interval on screen
+    }
+
+    void update(uint32_t nowMs) {
+        if (_rotateIntervalMs == 0 || _pages.size() < 2) return;
+        if (nowMs - _lastChangeMs >= _rotateIntervalMs) {
+            next(nowMs);
+        }
+    }
+
+    uint8_t getCurrentPage() const { return _current; }
+
+    void draw(IDisplayHal& display, int16_t x, int16_t y, int16_t w, int16_t h) override {
+        if (_pages.empty()) return;
+        const uint8_t current = _current;
+        _pages[current]->draw(display, x, y, w, h);
+
+        // Page indicator: a column of dots on the right edge, current one filled
+        const int16_t dotX = x + w - 3;
+        for (size_t i = 0; i < _pages.size(); i++) {
+            const int16_t dotY = y + 2 + (int16_t)i * 5;
+            if (i == current) {
+                display.fillRect(dotX, dotY, 2, 2);
+            } else {
+                display.setPixel(dotX, dotY);
+            }
+        }
+    }
+
+private:
+    std::vector<UIElement*> _pages;
+    uint32_t _rotateIntervalMs;
+    uint32_t _lastChangeMs = 0;
+    volatile uint8_t _current = 0;
+};
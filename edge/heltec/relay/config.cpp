@@ -1,10 +1,15 @@
 #include "config.h"
+#include "lib/board_config.h"
 
 #define RELAY_DEVICE_ID 1
 
 RelayConfig buildRelayConfig() {
     RelayConfig cfg = RelayConfig::create(RELAY_DEVICE_ID);
     cfg.deviceName = "relay-01";
+
+    // Heltec V3 VBAT divider on GPIO1, switched on by GPIO37
+    cfg.battery.adcPin = BATTERY_ADC_PIN;
+    cfg.battery.ctrlPin = VBAT_CTRL;
     
     // Override per-device values here (single source of truth for relay)
     cfg.communication.wifi.enableWifi = true;
@@ -21,6 +26,8 @@ RelayConfig buildRelayConfig() {
     cfg.communication.mqtt.clientId = "relay-1"; // MQTT client ID should be a string
     // Leave deviceTopic null to publish under baseTopic/<srcId>
     cfg.communication.mqtt.deviceTopic = nullptr;
+    // Retained online/offline under baseTopic/relay/status (last will on disconnect)
+    cfg.communication.mqtt.statusTopic = "relay/status";
     
     // Enhanced reliability settings
     cfg.communication.mqtt.connectionTimeoutMs = 15000;    // 15 second timeout
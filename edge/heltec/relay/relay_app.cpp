@@ -6,20 +6,30 @@
 #include "lib/core_system.h"
 #include "lib/core_scheduler.h"
 #include "lib/core_logger.h"
+#include "lib/core_watchdog.h"
+#include "lib/core_self_test.h"
 #include "lib/hal_display.h"
 #include "lib/hal_lora.h"
 #include "lib/hal_wifi.h"
 #include "lib/hal_battery.h"
+#include "lib/hal_button.h"
+#include "lib/hal_led.h"
 #include "lib/svc_ui.h"
 #include "lib/svc_comms.h"
 #include "lib/svc_battery.h"
 #include "lib/svc_wifi.h"
 #include "lib/svc_lora.h"
+#include "lib/svc_radio_config.h"
+#include "lib/svc_log_stream.h"
 #include "lib/ui_text_element.h"
 #include "lib/ui_icon_element.h"
 #include "lib/ui_battery_icon_element.h"
 #include "lib/ui_header_status_element.h"
+#include "lib/ui_paged_element.h"
+#include "lib/board_config.h"
+#include "lib/telemetry_keys.h"
 #include "config.h"
+#include <map>
 #include <memory>
 #include "lib/hal_persistence.h"
 #include "remote_device_manager.h"
@@ -39,38 +49,96 @@ private:
     CoreSystem coreSystem;
     CoreScheduler scheduler;
     CommonAppState appState;
+    CoreWatchdog watchdog;
 
     // HALs and Services (unique_ptrs are safer)
     std::unique_ptr<IDisplayHal> displayHal;
     std::unique_ptr<ILoRaHal> loraHal;
     std::unique_ptr<IWifiHal> wifiHal;
     std::unique_ptr<IBatteryHal> batteryHal;
+    std::unique_ptr<IButtonHal> buttonHal;
+    std::unique_ptr<ILedHal> ledHal;
 
     std::unique_ptr<UiService> uiService;
     std::unique_ptr<CommsService> commsService;
     std::unique_ptr<IBatteryService> batteryService;
     std::unique_ptr<IWifiService> wifiService;
     std::unique_ptr<ILoRaService> loraService;
+    std::unique_ptr<IRadioConfigService> radioConfigService;
+    std::unique_ptr<ILogStreamService> logStreamService;
     std::unique_ptr<IPersistenceHal> persistenceHal;
     std::unique_ptr<RemoteDeviceManager> deviceManager;
 
     // UI Elements
     std::vector<std::shared_ptr<UIElement>> uiElements;
-    std::shared_ptr<TextElement> mqttStatusText;
+    std::shared_ptr<PagedElement> statusPages;
+    std::shared_ptr<TextElement> linkPageText;
+    std::shared_ptr<TextElement> countersPageText;
+    std::shared_ptr<TextElement> uplinkPageText;
     std::shared_ptr<BatteryIconElement> batteryElement;
     std::shared_ptr<HeaderStatusElement> peerStatusElement;
     std::shared_ptr<HeaderStatusElement> wifiStatusElement;
 
     uint32_t _errorCount = 0;
+    // Persistent health counters (never reset by the daily reset)
+    uint32_t _radioResetCount = 0;
+    uint32_t _watchdogResetCount = 0;
+    uint32_t _radioResetsSeen = 0; // LoRa link radioResets already folded into _radioResetCount
+    bool _batteryLow = false;
+
+    // Failure classes, shown as "E<n>" on the OLED and as n blinks of the LED
+    enum class Fault : uint8_t {
+        None = 0,
+        Radio = 1,
+        Wifi = 2,
+        Mqtt = 3
+    };
+    Fault _fault = Fault::None;
 
     // Application-level message statistics
     struct MqttMessageStats {
         uint32_t successful = 0;
         uint32_t failed = 0;
+        uint32_t bytes = 0;
     } mqttStats;
 
+    // Uplink throughput over the last full minute, for the status screen
+    struct UplinkRate {
+        uint32_t windowStartMs = 0;
+        uint32_t windowStartMessages = 0;
+        uint32_t windowStartBytes = 0;
+        uint32_t messagesPerMin = 0;
+        uint32_t bytesPerMin = 0;
+    } uplinkRate;
+
+    // Last online/offline state published per node; cleared when MQTT drops
+    // so the full set is re-sent after a reconnect
+    std::map<uint8_t, bool> _publishedNodeOnline;
+    bool _nodeStatusMqttReady = false;
+
+    // Received frames wait in a fixed ring of slots between the radio callback
+    // (loop task) and the "rx_frames" task, so nothing is allocated per packet
+    // and MQTT publishing never runs inside RX handling
+    struct RxFrame {
+        uint8_t srcId;
+        uint8_t length;
+        int16_t rssiDbm;
+        int8_t snrDb;
+        uint8_t data[LORA_COMM_MAX_PAYLOAD];
+    };
+    static constexpr uint8_t kRxFrameSlots = 8;
+    RxFrame _rxFrames[kRxFrameSlots];
+    uint8_t _rxFrameHead = 0;
+    uint8_t _rxFrameCount = 0;
+    uint32_t _rxFramesDropped = 0;
+    uint32_t _rxFramesDroppedReported = 0;
+    portMUX_TYPE _rxFrameLock = portMUX_INITIALIZER_UNLOCKED;
+
     // LoRa message handling
     void onLoraDataReceived(uint8_t srcId, const uint8_t* payload, uint8_t length);
+    bool takeRxFrame(RxFrame& out);
+    void processRxFrames();
+    void handleRxFrame(const RxFrame& frame);
     void onLoraAckReceived(uint8_t srcId, uint16_t messageId, uint8_t attempts);
 
     // Static callback functions for LoRa HAL
@@ -81,6 +149,16 @@ private:
     static RelayApplicationImpl* callbackInstance;
 
     void setupUi();
+    void updateStatusPages(uint32_t nowMs);
+    void reportNeighbors(uint32_t nowMs);
+    void publishStatus(uint32_t nowMs);
+    void publishNodeStatus();
+    void publishInfo();
+    void checkBattery(uint32_t nowMs);
+    bool needsAttention();
+    Fault detectFault();
+    void checkFault();
+    void updateStatusLed(uint32_t nowMs);
 };
 
 RelayApplicationImpl::RelayApplicationImpl() :
@@ -107,6 +185,13 @@ void RelayApplicationImpl::initialize() {
     // Load persistent state
     persistenceHal->begin("app_state");
     _errorCount = persistenceHal->loadU32("errorCount", 0);
+    _radioResetCount = persistenceHal->loadU32("radioResets", 0);
+    _watchdogResetCount = persistenceHal->loadU32("wdtResets", 0);
+    if (CoreWatchdog::lastResetWasWatchdog()) {
+        _watchdogResetCount++;
+        persistenceHal->saveU32("wdtResets", _watchdogResetCount);
+        LOGW("Relay", "Recovered from a watchdog reset (%lu total)", (unsigned long)_watchdogResetCount);
+    }
     persistenceHal->end();
 
     // Create self-contained HALs
@@ -114,11 +199,16 @@ void RelayApplicationImpl::initialize() {
     loraHal = std::make_unique<LoRaCommHal>();
     loraHal->setPeerTimeout(config.peerTimeoutMs);
     loraHal->setVerbose(config.communication.usb.verboseLogging);
+    loraHal->setDutyCycleLimit(config.communication.lora.dutyCyclePermille);
+    loraHal->setRxSilenceTimeout(config.radioSilenceResetMs);
     batteryHal = std::make_unique<BatteryMonitorHal>(config.battery);
+    buttonHal = std::make_unique<GpioButtonHal>(PRG_BUTTON_PIN);
+    ledHal = std::make_unique<GpioLedHal>(STATUS_LED_PIN);
 
     // Create the device manager
     deviceManager = std::make_unique<RemoteDeviceManager>(loraHal.get(), persistenceHal.get());
     deviceManager->begin();
+    deviceManager->configureUplinkSlots(config.uplinkSlotCount, config.uplinkSlotCycleMs, config.uplinkSlotGuardMs);
 
     // Create services
     uiService = std::make_unique<UiService>(*displayHal);
@@ -126,6 +216,7 @@ void RelayApplicationImpl::initialize() {
     commsService->setLoraHal(loraHal.get());
     batteryService = std::make_unique<BatteryService>(*batteryHal);
     loraService = std::make_unique<LoRaService>(*loraHal);
+    radioConfigService = std::make_unique<RadioConfigService>(*loraHal, *persistenceHal, config.communication.lora, Serial);
 
     // Only create WiFi components if WiFi is enabled
     if (config.communication.wifi.enableWifi) {
@@ -150,6 +241,7 @@ void RelayApplicationImpl::initialize() {
         mqttConfig.deviceTopic = config.communication.mqtt.deviceTopic;
         mqttConfig.qos = config.communication.mqtt.qos;
         mqttConfig.retain = config.communication.mqtt.retain;
+        mqttConfig.statusTopic = config.communication.mqtt.statusTopic;
         
         // Pass through reliability settings
         mqttConfig.connectionTimeoutMs = config.communication.mqtt.connectionTimeoutMs;
@@ -166,13 +258,27 @@ void RelayApplicationImpl::initialize() {
                      mqttConfig.brokerPort,
                      mqttConfig.clientId ? mqttConfig.clientId : "null",
                      mqttConfig.baseTopic ? mqttConfig.baseTopic : "null");
+
+        if (config.logStreamLevel <= static_cast<uint8_t>(Logger::Level::Verbose)) {
+            logStreamService = std::make_unique<LogStreamService>(*wifiHal, "relay/log", config.logStreamMaxPerMinute);
+            logStreamService->begin(static_cast<Logger::Level>(config.logStreamLevel));
+        }
     } else {
         Serial.println("[Relay] MQTT not configured - check WiFi and MQTT enable flags");
     }
     
     // Begin hardware
     displayHal->begin();
+    buttonHal->begin();
+    ledHal->begin();
+    radioConfigService->begin(); // Flash overrides must be in place before the radio starts
+    CoreSelfTest selfTest;
+    if (buttonHal->isDown()) {
+        selfTest.run(*displayHal, config.selfTestToneSeconds);
+        delay(5000); // Leave the result on screen, then boot normally
+    }
     loraHal->begin(ILoRaHal::Mode::Master, config.deviceId);
+    selfTest.sendTestTone(*loraHal, millis());
 
     // Only begin WiFi if enabled and WiFi HAL exists
     if (config.communication.wifi.enableWifi && wifiHal) {
@@ -184,6 +290,7 @@ void RelayApplicationImpl::initialize() {
     loraHal->setOnAckReceived(&RelayApplicationImpl::staticOnAckReceived);
 
     uiService->init(); // Show splash screen
+    uiService->configurePower(config.displayDimAfterMs, config.displaySleepAfterMs, config.displayShiftIntervalMs);
     setupUi();
 
     
@@ -196,11 +303,31 @@ void RelayApplicationImpl::initialize() {
         if (batteryElement) {
             batteryElement->setStatus(batteryService->getBatteryPercent(), batteryService->isCharging());
         }
+        checkBattery(state.nowMs);
     }, 1000);
     
     scheduler.registerTask("display", [this](CommonAppState& state){
         uiService->tick();
     }, config.displayUpdateIntervalMs);
+
+    scheduler.registerTask("status_pages", [this](CommonAppState& state){
+        checkFault();
+        updateStatusPages(state.nowMs);
+        if (needsAttention()) {
+            uiService->wake(state.nowMs); // Keep the display on until the problem clears
+        }
+    }, 1000);
+
+    scheduler.registerTask("status_led", [this](CommonAppState& state){
+        updateStatusLed(state.nowMs);
+    }, 100);
+
+    // PRG button flips to the next status page; the first press only wakes the display
+    scheduler.registerTask("button", [this](CommonAppState& state){
+        if (buttonHal->wasPressed(state.nowMs) && !uiService->wake(state.nowMs) && statusPages) {
+            statusPages->next(state.nowMs); // Redrawn on the next display tick
+        }
+    }, 20);
     
     // Daily reset task for relay's own counters
     scheduler.registerTask("daily_reset", [this](CommonAppState& state){
@@ -232,6 +359,51 @@ void RelayApplicationImpl::initialize() {
             peerStatusElement->setPeerCount(hasActivePeers ? loraService->getPeerCount() : 0);
         }
     }, 50);
+
+    scheduler.registerTask("rx_frames", [this](CommonAppState& state){
+        processRxFrames();
+    }, 20);
+
+    scheduler.registerTask("radio_config", [this](CommonAppState& state){
+        radioConfigService->update(state.nowMs);
+    }, 100);
+
+    scheduler.registerTask("neighbors", [this](CommonAppState& state){
+        reportNeighbors(state.nowMs);
+    }, config.neighborReportIntervalMs);
+
+    // Persist radio recoveries performed by the LoRa layer
+    scheduler.registerTask("radio_health", [this](CommonAppState& state){
+        const uint32_t resets = loraHal->getLinkStats().radioResets;
+        if (resets != _radioResetsSeen) {
+            _radioResetCount += resets - _radioResetsSeen;
+            _radioResetsSeen = resets;
+            persistenceHal->begin("app_state");
+            persistenceHal->saveU32("radioResets", _radioResetCount);
+            persistenceHal->end();
+            LOGW("Relay", "Radio recovered (%lu total)", (unsigned long)_radioResetCount);
+        }
+    }, 10000);
+
+    // Nodes go offline after config.peerTimeoutMs without a frame
+    scheduler.registerTask("node_status", [this](CommonAppState& state){
+        publishNodeStatus();
+    }, config.peerMonitorIntervalMs);
+
+    scheduler.registerTask("status_report", [this](CommonAppState& state){
+        publishStatus(state.nowMs);
+    }, config.statusReportIntervalMs);
+
+    // All scheduler tasks share the FreeRTOS timer task; feeding from one
+    // proves none of them is stuck. The loop task is fed from run().
+    scheduler.registerTask("watchdog", [this](CommonAppState& state){
+        static bool subscribed = false;
+        if (!subscribed) {
+            watchdog.subscribeCurrentTask();
+            subscribed = true;
+        }
+        watchdog.feed();
+    }, config.watchdogTimeoutMs / 4);
     
     if (config.communication.wifi.enableWifi && wifiService) {
         scheduler.registerTask("wifi", [this](CommonAppState& state){
@@ -239,20 +411,18 @@ void RelayApplicationImpl::initialize() {
             if (wifiStatusElement) {
                 wifiStatusElement->setWifiStatus(wifiService->isConnected(), wifiService->getSignalStrengthPercent());
             }
-            if (mqttStatusText) {
-                bool connected = wifiService->isMqttConnected();
-                char statusText[40];
-                if (connected) {
-                    snprintf(statusText, sizeof(statusText), "MQTT OK\nErrors: %u", _errorCount);
-                } else {
-                    snprintf(statusText, sizeof(statusText), "MQTT X\nErrors: %u", _errorCount);
-                }
-                mqttStatusText->setText(statusText);
-            }
         }, config.communication.wifi.statusCheckIntervalMs);
     }
-    
-    
+
+    if (logStreamService) {
+        scheduler.registerTask("log_stream", [this](CommonAppState& state){
+            logStreamService->update(state.nowMs);
+        }, 1000);
+    }
+
+    watchdog.begin(config.watchdogTimeoutMs);
+    watchdog.subscribeCurrentTask(); // Arduino loop task, fed from run()
+
     scheduler.start(appState);
 }
 
@@ -289,24 +459,347 @@ void RelayApplicationImpl::setupUi() {
     topBar.setColumn(TopBarColumn::Network, peerStatusElement.get());
 
     // -- Main Content --
-    // [Small Logo] [Status Text]
+    // [Small Logo] [Status pages: link, counters, uplink]
     mainContent.setLeftColumnWidth(logo_small_width + 8); // logo width + more margin
     auto logoElement = std::make_shared<IconElement>(logo_small_bits, logo_small_width, logo_small_height);
     uiElements.push_back(logoElement);
     mainContent.setLeft(logoElement.get());
 
-    mqttStatusText = std::make_shared<TextElement>();
-    mqttStatusText->setText("MQTT...");
-    uiElements.push_back(mqttStatusText);
-    mainContent.setRight(mqttStatusText.get());
+    linkPageText = std::make_shared<TextElement>();
+    countersPageText = std::make_shared<TextElement>();
+    uplinkPageText = std::make_shared<TextElement>();
+    uplinkPageText->setText("MQTT...");
+    uiElements.push_back(linkPageText);
+    uiElements.push_back(countersPageText);
+    uiElements.push_back(uplinkPageText);
+
+    statusPages = std::make_shared<PagedElement>(config.statusPageIntervalMs);
+    statusPages->addPage(linkPageText.get());
+    statusPages->addPage(countersPageText.get());
+    statusPages->addPage(uplinkPageText.get());
+    uiElements.push_back(statusPages);
+    mainContent.setRight(statusPages.get());
+}
+
+void RelayApplicationImpl::updateStatusPages(uint32_t nowMs) {
+    if (!statusPages) return;
+    statusPages->update(nowMs);
+
+    const ILoRaHal::LinkStats link = loraHal->getLinkStats();
+    char text[64];
+
+    // Page 1: uptime and signal of the last frame heard
+    const uint32_t upSec = nowMs / 1000;
+    char uptime[16];
+    if (upSec >= 86400) {
+        snprintf(uptime, sizeof(uptime), "%lud%02luh", (unsigned long)(upSec / 86400), (unsigned long)((upSec / 3600) % 24));
+    } else {
+        snprintf(uptime, sizeof(uptime), "%02lu:%02lu:%02lu", (unsigned long)(upSec / 3600),
+                 (unsigned long)((upSec / 60) % 60), (unsigned long)(upSec % 60));
+    }
+    if (_fault == Fault::Radio) {
+        snprintf(text, sizeof(text), "Up %s\nE1 RADIO FAIL\nRetrying", uptime);
+    } else if (link.rxFrames == 0) {
+        snprintf(text, sizeof(text), "Up %s\nRSSI --\nSNR --", uptime);
+    } else {
+        snprintf(text, sizeof(text), "Up %s\nRSSI %d dBm\nSNR %d dB", uptime, (int)link.lastRssiDbm, (int)link.lastSnrDb);
+    }
+    const ILoRaHal::DutyCycleStatus duty = loraHal->getDutyCycleStatus();
+    if (duty.limitPermille != 0) {
+        const size_t used = strlen(text);
+        if (duty.throttled) {
+            snprintf(text + used, sizeof(text) - used, "\nDC LIMIT");
+        } else {
+            // Airtime used this hour as a share of the budget
+            snprintf(text + used, sizeof(text) - used, "\nDC %lu%%",
+                     (unsigned long)(duty.budgetMs ? (uint64_t)duty.usedMs * 100 / duty.budgetMs : 0));
+        }
+    }
+    linkPageText->setText(text);
+
+    // Page 2: packet counters since boot (errors follow the persisted daily count)
+    snprintf(text, sizeof(text), "RX %lu\nTX %lu\nFwd %lu\nErr %lu",
+             (unsigned long)link.rxFrames, (unsigned long)link.txFrames,
+             (unsigned long)mqttStats.successful, (unsigned long)_errorCount);
+    countersPageText->setText(text);
+
+    // Page 3: uplink throughput. The relay's uplink is MQTT over WiFi.
+    if (nowMs - uplinkRate.windowStartMs >= 60000) {
+        uplinkRate.messagesPerMin = mqttStats.successful - uplinkRate.windowStartMessages;
+        uplinkRate.bytesPerMin = mqttStats.bytes - uplinkRate.windowStartBytes;
+        uplinkRate.windowStartMs = nowMs;
+        uplinkRate.windowStartMessages = mqttStats.successful;
+        uplinkRate.windowStartBytes = mqttStats.bytes;
+    }
+    if (!wifiService) {
+        snprintf(text, sizeof(text), "WiFi off");
+    } else if (_fault == Fault::Wifi) {
+        snprintf(text, sizeof(text), "E2 WiFi down");
+    } else {
+        snprintf(text, sizeof(text), "%s\n%lu msg/min\n%lu B/min",
+                 _fault == Fault::Mqtt ? "E3 MQTT down" : (wifiService->isMqttConnected() ? "MQTT OK" : "MQTT X"),
+                 (unsigned long)uplinkRate.messagesPerMin, (unsigned long)uplinkRate.bytesPerMin);
+    }
+    uplinkPageText->setText(text);
+}
+
+void RelayApplicationImpl::reportNeighbors(uint32_t nowMs) {
+    // One line per known node: "id=3 up age=12s rssi=-81 snr=7 rx=118 dup=2"
+    const size_t total = loraHal->getTotalPeerCount();
+    LOGI("Nodes", "%u known, %u up", (unsigned)total, (unsigned)loraHal->getPeerCount());
+    ILoRaHal::PeerInfo peer;
+    for (size_t i = 0; i < total; i++) {
+        if (!loraHal->getPeerByIndex(i, peer)) break;
+        LOGI("Nodes", "id=%u %s age=%lus rssi=%d snr=%d rx=%lu dup=%lu",
+             peer.peerId, peer.connected ? "up" : "down",
+             (unsigned long)((nowMs - peer.lastSeenMs) / 1000),
+             (int)peer.lastRssiDbm, (int)peer.lastSnrDb,
+             (unsigned long)peer.rxFrames, (unsigned long)peer.duplicates);
+    }
+}
+
+void RelayApplicationImpl::checkBattery(uint32_t nowMs) {
+    if (batteryService->getVoltageMilliVolts() == 0) return; // No battery sense on this board
+
+    const uint8_t percent = batteryService->getBatteryPercent();
+    const bool low = _batteryLow ? percent < config.lowBatteryPercent + 5
+                                 : percent < config.lowBatteryPercent;
+    if (low == _batteryLow) return;
+
+    _batteryLow = low;
+    if (low) {
+        LOGW("Relay", "Battery low: %u%%", percent);
+    } else {
+        LOGI("Relay", "Battery recovered: %u%%", percent);
+    }
+    publishStatus(nowMs); // Don't wait for the next report to raise or clear the alarm
+}
+
+void RelayApplicationImpl::publishStatus(uint32_t nowMs) {
+    if (!config.communication.wifi.enableWifi || !wifiHal->isMqttReady()) {
+        return; // Nothing queued; the next report carries the same counters
+    }
+
+    // Same key:value format as forwarded telemetry, on "farm/telemetry/relay"
+    const ILoRaHal::LinkStats link = loraHal->getLinkStats();
+    const ILoRaHal::DutyCycleStatus duty = loraHal->getDutyCycleStatus();
+    const uint32_t dutyPercent = duty.budgetMs > 0 ? duty.usedMs * 100 / duty.budgetMs : 0;
+    char payload[240];
+    size_t length = 0;
+    // snprintf returns the untruncated length; keep only what fit so the
+    // next append never starts past the end of the buffer
+    auto advance = [&](int written) {
+        if (written <= 0) return;
+        const size_t room = sizeof(payload) - length;
+        length += (size_t)written < room ? (size_t)written : room - 1;
+    };
+    advance(snprintf(payload, sizeof(payload),
+                     "%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%lu,%s:%u,%s:%u",
+                     TelemetryKeys::Uptime, (unsigned long)(nowMs / 1000),
+                     TelemetryKeys::FreeHeap, (unsigned long)ESP.getFreeHeap(),
+                     TelemetryKeys::MinFreeHeap, (unsigned long)ESP.getMinFreeHeap(),
+                     TelemetryKeys::MaxHeapBlock, (unsigned long)ESP.getMaxAllocHeap(),
+                     TelemetryKeys::RxFrames, (unsigned long)link.rxFrames,
+                     TelemetryKeys::TxFrames, (unsigned long)link.txFrames,
+                     TelemetryKeys::CrcErrors, (unsigned long)link.crcErrors,
+                     TelemetryKeys::RadioResets, (unsigned long)_radioResetCount,
+                     TelemetryKeys::WatchdogResets, (unsigned long)_watchdogResetCount,
+                     TelemetryKeys::DutyCycleUsed, (unsigned long)dutyPercent,
+                     TelemetryKeys::PeerCount, (unsigned)loraService->getPeerCount(),
+                     TelemetryKeys::FaultCode, (unsigned)_fault));
+    if (link.lastRxMs != 0) {
+        advance(snprintf(payload + length, sizeof(payload) - length, ",%s:%d,%s:%d",
+                         TelemetryKeys::Rssi, (int)link.lastRssiDbm,
+                         TelemetryKeys::Snr, (int)link.lastSnrDb));
+    }
+    if (config.uplinkSlotCount > 0) {
+        advance(snprintf(payload + length, sizeof(payload) - length, ",%s:%lu",
+                         TelemetryKeys::SlotMisses, (unsigned long)deviceManager->getSlotMisses()));
+    }
+    const uint16_t batteryMv = batteryService->getVoltageMilliVolts();
+    if (batteryMv != 0) {
+        advance(snprintf(payload + length, sizeof(payload) - length, ",%s:%u,%s:%u,%s:%u,%s:%u",
+                         TelemetryKeys::BatteryPercent, (unsigned)batteryService->getBatteryPercent(),
+                         TelemetryKeys::BatteryMilliVolts, (unsigned)batteryMv,
+                         TelemetryKeys::Charging, batteryService->isCharging() ? 1u : 0u,
+                         TelemetryKeys::BatteryLow, _batteryLow ? 1u : 0u));
+    }
+
+    if (length > UINT8_MAX) {
+        LOGW("Relay", "Relay status too long to publish (%u bytes)", (unsigned)length);
+        return;
+    }
+    if (!wifiHal->publishMqtt("relay", reinterpret_cast<const uint8_t*>(payload), (uint8_t)length)) {
+        LOGW("Relay", "Failed to publish relay status");
+        mqttStats.failed++;
+    } else {
+        LOGD("Relay", "Published relay status: %s", payload);
+    }
+}
+
+// Conditions worth seeing on the OLED without pressing a button
+bool RelayApplicationImpl::needsAttention() {
+    return _fault != Fault::None || _batteryLow || loraHal->getDutyCycleStatus().throttled;
+}
+
+// Most severe first: without the radio nothing reaches the relay at all
+RelayApplicationImpl::Fault RelayApplicationImpl::detectFault() {
+    if (!loraHal->isRadioResponding()) return Fault::Radio;
+    if (config.communication.wifi.enableWifi && wifiService && !wifiService->isConnected()) return Fault::Wifi;
+    if (config.communication.mqtt.enableMqtt && wifiHal && !wifiHal->isMqttReady()) return Fault::Mqtt;
+    return Fault::None;
+}
+
+void RelayApplicationImpl::checkFault() {
+    static const char* const kFaultNames[] = {"none", "radio not responding", "WiFi down", "MQTT down"};
+    const Fault fault = detectFault();
+    if (fault == _fault) return;
+    if (fault == Fault::None) {
+        LOGI("Relay", "Fault E%u cleared (%s)", (unsigned)_fault, kFaultNames[(uint8_t)_fault]);
+    } else {
+        LOGW("Relay", "Fault E%u: %s", (unsigned)fault, kFaultNames[(uint8_t)fault]);
+    }
+    _fault = fault;
+}
+
+void RelayApplicationImpl::updateStatusLed(uint32_t nowMs) {
+    // n blinks of 200 ms, then a pause so the code can be counted; dark when healthy
+    const uint32_t blinks = (uint32_t)_fault;
+    const uint32_t periodMs = blinks * 400 + 1200;
+    const uint32_t phaseMs = nowMs % periodMs;
+    ledHal->set(blinks > 0 && phaseMs < blinks * 400 && phaseMs % 400 < 200);
+}
+
+// Retained snapshot of what this relay runs, refreshed on every MQTT connect
+void RelayApplicationImpl::publishInfo() {
+    // Build stamp from __DATE__ ("Oct 16 2026") and __TIME__ ("14:32:05") as 20261016.143205
+    static const char kMonths[] = "JanFebMarAprMayJunJulAugSepOctNovDec";
+    const char* date = __DATE__;
+    const char* time = __TIME__;
+    char monthName[4] = {date[0], date[1], date[2], '\0'};
+    const char* month = strstr(kMonths, monthName);
+    char build[16];
+    snprintf(build, sizeof(build), "%.4s%02d%02d.%c%c%c%c%c%c",
+             date + 7, month ? (int)(month - kMonths) / 3 + 1 : 0, atoi(date + 4),
+             time[0], time[1], time[3], time[4], time[6], time[7]);
+
+    const ILoRaHal::RadioSettings& radio = radioConfigService->getSettings();
+    char payload[160];
+    snprintf(payload, sizeof(payload), "%s:%s,%s:%u,%s:%lu,%s:%u,%s:%u,%s:%u,%s:%d,%s:%u,%s:%u",
+             TelemetryKeys::Build, build,
+             TelemetryKeys::DeviceId, (unsigned)config.deviceId,
+             TelemetryKeys::Frequency, (unsigned long)radio.frequencyHz,
+             TelemetryKeys::SpreadingFactor, (unsigned)radio.spreadingFactor,
+             TelemetryKeys::Bandwidth, (unsigned)radio.bandwidth,
+             TelemetryKeys::CodingRate, (unsigned)radio.codingRate,
+             TelemetryKeys::TxPower, (int)radio.txPowerDbm,
+             TelemetryKeys::LinkKey, radioConfigService->hasKey() ? 1u : 0u,
+             TelemetryKeys::NodeCount, deviceManager ? (unsigned)deviceManager->getDeviceCount() : 0u);
+    if (!wifiHal->publishMqttRetained("relay/info", payload)) {
+        LOGW("Relay", "Failed to publish relay info");
+    } else {
+        LOGI("Relay", "Published relay info: %s", payload);
+    }
+}
+
+void RelayApplicationImpl::publishNodeStatus() {
+    const bool mqttReady = config.communication.wifi.enableWifi && wifiHal->isMqttReady();
+    if (mqttReady != _nodeStatusMqttReady) {
+        _nodeStatusMqttReady = mqttReady;
+        _publishedNodeOnline.clear();
+        if (mqttReady) {
+            publishInfo();
+        }
+    }
+    if (!mqttReady) return;
+
+    // Retained "online"/"offline" on "<baseTopic>/remote-{id}/status"
+    const size_t total = loraHal->getTotalPeerCount();
+    ILoRaHal::PeerInfo peer;
+    for (size_t i = 0; i < total; i++) {
+        if (!loraHal->getPeerByIndex(i, peer)) break;
+        auto it = _publishedNodeOnline.find(peer.peerId);
+        if (it != _publishedNodeOnline.end() && it->second == peer.connected) continue;
+
+        char topicSuffix[32];
+        snprintf(topicSuffix, sizeof(topicSuffix), "remote-%u/status", peer.peerId);
+        if (wifiHal->publishMqttRetained(topicSuffix, peer.connected ? "online" : "offline")) {
+            _publishedNodeOnline[peer.peerId] = peer.connected;
+            LOGI("Relay", "Device %u is %s", peer.peerId, peer.connected ? "online" : "offline");
+        }
+    }
 }
 
 void RelayApplicationImpl::onLoraDataReceived(uint8_t srcId, const uint8_t* payload, uint8_t length) {
+    if (length > LORA_COMM_MAX_PAYLOAD) {
+        LOGW("Relay", "Dropping %u-byte frame from device %u: too long", length, srcId);
+        return;
+    }
+    // The data callback runs inside RX handling, so link stats describe this frame
+    const ILoRaHal::LinkStats link = loraHal->getLinkStats();
+
+    portENTER_CRITICAL(&_rxFrameLock);
+    if (_rxFrameCount >= kRxFrameSlots) {
+        _rxFramesDropped++;
+    } else {
+        RxFrame& slot = _rxFrames[(_rxFrameHead + _rxFrameCount) % kRxFrameSlots];
+        slot.srcId = srcId;
+        slot.length = length;
+        slot.rssiDbm = link.lastRssiDbm;
+        slot.snrDb = link.lastSnrDb;
+        memcpy(slot.data, payload, length);
+        _rxFrameCount++;
+    }
+    portEXIT_CRITICAL(&_rxFrameLock);
+}
+
+bool RelayApplicationImpl::takeRxFrame(RxFrame& out) {
+    bool ok = false;
+    portENTER_CRITICAL(&_rxFrameLock);
+    if (_rxFrameCount > 0) {
+        out = _rxFrames[_rxFrameHead];
+        _rxFrameHead = (_rxFrameHead + 1) % kRxFrameSlots;
+        _rxFrameCount--;
+        ok = true;
+    }
+    portEXIT_CRITICAL(&_rxFrameLock);
+    return ok;
+}
+
+void RelayApplicationImpl::processRxFrames() {
+    portENTER_CRITICAL(&_rxFrameLock);
+    const uint32_t dropped = _rxFramesDropped;
+    portEXIT_CRITICAL(&_rxFrameLock);
+    if (dropped != _rxFramesDroppedReported) {
+        LOGW("Relay", "%lu received frames dropped: all %u slots busy",
+             (unsigned long)(dropped - _rxFramesDroppedReported), kRxFrameSlots);
+        _rxFramesDroppedReported = dropped;
+    }
+
+    RxFrame frame;
+    while (takeRxFrame(frame)) {
+        handleRxFrame(frame);
+    }
+}
+
+void RelayApplicationImpl::handleRxFrame(const RxFrame& frame) {
+    const uint8_t srcId = frame.srcId;
+    const uint8_t* payload = frame.data;
+    const uint8_t length = frame.length;
+
+    // Range test frames ("test:N,...") are reported separately and are not telemetry
+    const size_t testKeyLength = strlen(TelemetryKeys::RangeTest);
+    const bool isRangeTest = length > testKeyLength &&
+                             memcmp(payload, TelemetryKeys::RangeTest, testKeyLength) == 0 &&
+                             payload[testKeyLength] == ':';
+    if (isRangeTest) {
+        LOGI("Relay", "Range test from device %u: %.*s rssi=%d snr=%d",
+             srcId, length, reinterpret_cast<const char*>(payload), (int)frame.rssiDbm, (int)frame.snrDb);
+    }
+
     // Pass telemetry to the device manager to handle state
-    if (deviceManager) {
-        // Convert payload to std::string for easier parsing
-        std::string payload_str(reinterpret_cast<const char*>(payload), length);
-        deviceManager->handleTelemetry(srcId, payload_str);
+    if (deviceManager && !isRangeTest) {
+        deviceManager->handleTelemetry(srcId, payload, length);
     }
     
     // Process received LoRa data and forward to MQTT if WiFi is available
@@ -316,18 +809,34 @@ void RelayApplicationImpl::onLoraDataReceived(uint8_t srcId, const uint8_t* payl
         LOGD("Relay", "MQTT not ready, cannot forward %u bytes from device %u (WiFi state: %s)",
              length, srcId, wifiHal->isConnected() ? "connected" : "disconnected");
     } else {
-        // Format MQTT topic as "farm/telemetry/remote-{srcId}"
+        // Format MQTT topic as "farm/telemetry/remote-{srcId}" ("remote-{srcId}/test" for range tests)
         char topicSuffix[32];
-        snprintf(topicSuffix, sizeof(topicSuffix), "remote-%u", srcId);
+        snprintf(topicSuffix, sizeof(topicSuffix), isRangeTest ? "remote-%u/test" : "remote-%u", srcId);
         char fullTopic[64];
         snprintf(fullTopic, sizeof(fullTopic), "farm/telemetry/%s", topicSuffix);
 
+        // Append link quality of this frame so it is tracked per remote
+        char forwarded[LORA_COMM_MAX_PAYLOAD + 32];
+        const size_t copied = length < LORA_COMM_MAX_PAYLOAD ? length : LORA_COMM_MAX_PAYLOAD;
+        memcpy(forwarded, payload, copied);
+        size_t forwardedLength = copied;
+        const int appended = snprintf(forwarded + forwardedLength, sizeof(forwarded) - forwardedLength,
+                                      "%s%s:%d,%s:%d", copied > 0 ? "," : "",
+                                      TelemetryKeys::Rssi, (int)frame.rssiDbm,
+                                      TelemetryKeys::Snr, (int)frame.snrDb);
+        if (appended > 0) {
+            // snprintf returns the untruncated length; count only what fit
+            forwardedLength += (size_t)appended < sizeof(forwarded) - forwardedLength
+                                   ? (size_t)appended
+                                   : sizeof(forwarded) - forwardedLength - 1;
+        }
+
         // Forward the sensor data to MQTT
         LOGD("Relay", "Attempting to publish %u bytes from device %u to MQTT topic '%s'",
-             length, srcId, fullTopic);
-        if (!wifiHal->publishMqtt(topicSuffix, payload, length)) {
+             (unsigned)forwardedLength, srcId, fullTopic);
+        if (!wifiHal->publishMqtt(topicSuffix, reinterpret_cast<const uint8_t*>(forwarded), (uint8_t)forwardedLength)) {
             LOGW("Relay", "Failed to publish %u bytes from device %u to MQTT topic '%s'",
-                 length, srcId, fullTopic);
+                 (unsigned)forwardedLength, srcId, fullTopic);
             mqttStats.failed++;
             _errorCount++;
             persistenceHal->begin("app_state");
@@ -335,8 +844,9 @@ void RelayApplicationImpl::onLoraDataReceived(uint8_t srcId, const uint8_t* payl
             persistenceHal->end();
         } else {
             LOGI("Relay", "Successfully published %u bytes from device %u to MQTT topic '%s'",
-                 length, srcId, fullTopic);
+                 (unsigned)forwardedLength, srcId, fullTopic);
             mqttStats.successful++;
+            mqttStats.bytes += forwardedLength;
         }
     }
 
@@ -351,6 +861,7 @@ void RelayApplicationImpl::run() {
     // The scheduler runs tasks in the background. We can use the main loop
     // for high-frequency polling of radio interrupts.
     Radio.IrqProcess();
+    watchdog.feed();
     vTaskDelay(pdMS_TO_TICKS(5)); // Yield to other tasks
 }
 
@@ -380,11 +891,15 @@ void RelayApplication::run() { impl->run(); }
 #include "lib/core_config.cpp"
 #include "lib/core_system.cpp"
 #include "lib/core_scheduler.cpp"
+#include "lib/core_watchdog.cpp"
+#include "lib/core_self_test.cpp"
 #include "lib/svc_ui.cpp"
 #include "lib/svc_comms.cpp"
 #include "lib/svc_battery.cpp"
 #include "lib/svc_wifi.cpp"
 #include "lib/svc_lora.cpp"
+#include "lib/svc_radio_config.cpp"
+#include "lib/svc_log_stream.cpp"
 #include "lib/ui_battery_icon_element.cpp"
 #include "lib/ui_header_status_element.cpp"
 #include "lib/ui_main_content_layout.cpp"
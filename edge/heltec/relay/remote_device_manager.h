@@ -17,6 +17,9 @@ struct RemoteDeviceState {
     unsigned long lastMessageMs = 0;
     uint32_t lastTsrSec = 0;
     bool needsSave = false;
+    uint8_t uplinkSlot = 0;        // Assigned on first telemetry; not persisted (the cycle restarts at boot)
+    bool hasUplinkSlot = false;
+    uint32_t lastSlotSyncMs = 0;
 };
 
 class RemoteDeviceManager {
@@ -25,12 +28,22 @@ public:
 
     void begin();
     void update(uint32_t nowMs);
-    void handleTelemetry(uint8_t srcId, const std::string& payload);
+    // Uplink slots: the cycle is split into slotCount slots from boot, one per
+    // remote. Frames arriving outside a remote's slot trigger a SetUplinkSlot
+    // downlink, which also corrects clock drift. slotCount 0 disables slotting.
+    void configureUplinkSlots(uint8_t slotCount, uint32_t cycleMs, uint32_t guardMs);
+    uint32_t getSlotMisses() const { return _slotMisses; }
+    // Remotes with stored state: every remote that has ever reported, kept across reboots
+    size_t getDeviceCount() const { return _devices.size(); }
+    // Parses "key:value,..." in place in a fixed buffer; no heap use per frame
+    void handleTelemetry(uint8_t srcId, const uint8_t* payload, uint8_t length);
 
 private:
     void loadAllStates();
     void saveState(RemoteDeviceState& state);
     void sendResetCommand(uint8_t deviceId);
+    void checkUplinkSlot(RemoteDeviceState& device, uint32_t nowMs);
+    void sendUplinkSlot(RemoteDeviceState& device, uint32_t nowMs);
 
     RemoteDeviceState* getOrCreateDevice(uint8_t deviceId);
 
@@ -38,6 +51,12 @@ private:
     IPersistenceHal* _persistence;
     std::map<uint8_t, RemoteDeviceState> _devices;
 
+    uint8_t _slotCount = 0;
+    uint32_t _slotCycleMs = 0;
+    uint32_t _slotGuardMs = 0;
+    uint8_t _nextSlot = 0;
+    uint32_t _slotMisses = 0;
+
     const uint32_t RESET_INTERVAL_MS = 24 * 60 * 60 * 1000; // 24 hours
 };
 
@@ -48,6 +67,18 @@ void RemoteDeviceManager::begin() {
     loadAllStates();
 }
 
+void RemoteDeviceManager::configureUplinkSlots(uint8_t slotCount, uint32_t cycleMs, uint32_t guardMs) {
+    _slotCount = cycleMs > 0 ? slotCount : 0;
+    _slotCycleMs = cycleMs;
+    _slotGuardMs = guardMs;
+    if (_slotCount == 0) return;
+    const uint32_t slotMs = _slotCycleMs / _slotCount;
+    LOGI("DeviceManager", "Uplink slots: %u x %lu ms, guard %lu ms", _slotCount, (unsigned long)slotMs, (unsigned long)_slotGuardMs);
+    if (slotMs < 2 * _slotGuardMs) {
+        LOGW("DeviceManager", "Uplink slots of %lu ms leave little room after the %lu ms guard", (unsigned long)slotMs, (unsigned long)_slotGuardMs);
+    }
+}
+
 void RemoteDeviceManager::update(uint32_t nowMs) {
     for (auto& pair : _devices) {
         RemoteDeviceState& device = pair.second;
@@ -71,47 +102,105 @@ void RemoteDeviceManager::update(uint32_t nowMs) {
     }
 }
 
-void RemoteDeviceManager::handleTelemetry(uint8_t srcId, const std::string& payload) {
+void RemoteDeviceManager::handleTelemetry(uint8_t srcId, const uint8_t* payload, uint8_t length) {
     unsigned long nowMs = millis();
     RemoteDeviceState* device = getOrCreateDevice(srcId);
     if (!device) return;
 
     device->lastMessageMs = nowMs;
 
-    size_t start = 0;
-    while (start < payload.length()) {
-        size_t end = payload.find(',', start);
-        if (end == std::string::npos) end = payload.length();
-        
-        std::string pair_str = payload.substr(start, end - start);
-        size_t colon_pos = pair_str.find(':');
-
-        if (colon_pos != std::string::npos) {
-            std::string key = pair_str.substr(0, colon_pos);
-            std::string value_str = pair_str.substr(colon_pos + 1);
-
-            if (key == TelemetryKeys::TotalVolume) {
-                if (value_str != "nan") device->dailyVolumeLiters = std::stof(value_str);
-            } else if (key == TelemetryKeys::ErrorCount) {
-                if (value_str != "nan") device->errorCount = std::stoul(value_str);
-            } else if (key == TelemetryKeys::TimeSinceReset) {
-                if (value_str != "nan") device->timeSinceResetSec = std::stoul(value_str);
-            } else if (key == TelemetryKeys::PulseDelta) {
-                if (value_str != "nan" && device->lastTsrSec > 0) {
-                    uint32_t time_delta_sec = device->timeSinceResetSec - device->lastTsrSec;
-                    uint16_t pulse_delta = std::stoul(value_str);
-                    if (time_delta_sec > 0) {
-                        float frequency = (float)pulse_delta / time_delta_sec;
-                        float flow_rate_lpm = (frequency * 60.0f) / 450.0f; // Using hardcoded const for now
-                        LOGD("DeviceManager", "Device %u flow rate: %.2f L/min (from %u pulses over %u s)", srcId, flow_rate_lpm, pulse_delta, time_delta_sec);
-                    }
+    char text[LORA_COMM_MAX_PAYLOAD + 1];
+    const size_t textLength = length < LORA_COMM_MAX_PAYLOAD ? length : LORA_COMM_MAX_PAYLOAD;
+    memcpy(text, payload, textLength);
+    text[textLength] = '\0';
+
+    char* savePtr = nullptr;
+    for (char* pair = strtok_r(text, ",", &savePtr); pair != nullptr; pair = strtok_r(nullptr, ",", &savePtr)) {
+        char* colon = strchr(pair, ':');
+        if (colon == nullptr) continue;
+        *colon = '\0';
+        const char* key = pair;
+        const char* value = colon + 1;
+        if (strcmp(value, "nan") == 0) continue;
+
+        // strto* instead of std::sto*: a malformed value is skipped, not thrown
+        char* end = nullptr;
+        strtof(value, &end);
+        if (end == value) continue;
+        if (strcmp(key, TelemetryKeys::TotalVolume) == 0) {
+            device->dailyVolumeLiters = strtof(value, nullptr);
+        } else if (strcmp(key, TelemetryKeys::ErrorCount) == 0) {
+            device->errorCount = strtoul(value, nullptr, 10);
+        } else if (strcmp(key, TelemetryKeys::TimeSinceReset) == 0) {
+            device->timeSinceResetSec = strtoul(value, nullptr, 10);
+        } else if (strcmp(key, TelemetryKeys::PulseDelta) == 0) {
+            if (device->lastTsrSec > 0) {
+                uint32_t time_delta_sec = device->timeSinceResetSec - device->lastTsrSec;
+                uint16_t pulse_delta = (uint16_t)strtoul(value, nullptr, 10);
+                if (time_delta_sec > 0) {
+                    float frequency = (float)pulse_delta / time_delta_sec;
+                    float flow_rate_lpm = (frequency * 60.0f) / 450.0f; // Using hardcoded const for now
+                    LOGD("DeviceManager", "Device %u flow rate: %.2f L/min (from %u pulses over %u s)", srcId, flow_rate_lpm, pulse_delta, time_delta_sec);
                 }
             }
         }
-        start = end + 1;
     }
     device->lastTsrSec = device->timeSinceResetSec;
     device->needsSave = true; // Mark for save on next update cycle
+
+    if (_slotCount > 0) {
+        checkUplinkSlot(*device, nowMs);
+    }
+}
+
+void RemoteDeviceManager::checkUplinkSlot(RemoteDeviceState& device, uint32_t nowMs) {
+    if (!device.hasUplinkSlot) {
+        if (_nextSlot >= _slotCount) {
+            LOGW("DeviceManager", "More remotes than uplink slots; device %u shares slot %u", device.deviceId, _nextSlot % _slotCount);
+        }
+        device.uplinkSlot = _nextSlot++ % _slotCount;
+        device.hasUplinkSlot = true;
+        sendUplinkSlot(device, nowMs);
+        return;
+    }
+
+    // Offset of this frame from the start of the device's slot, wrapped into the cycle
+    const uint32_t slotMs = _slotCycleMs / _slotCount;
+    const uint32_t slotStart = device.uplinkSlot * slotMs;
+    const uint32_t offset = (nowMs % _slotCycleMs + _slotCycleMs - slotStart) % _slotCycleMs;
+    if (offset < slotMs) return;
+
+    _slotMisses++;
+    // A correction is in flight until the remote's next report; don't repeat it
+    if (nowMs - device.lastSlotSyncMs < _slotCycleMs) return;
+    LOGD("DeviceManager", "Device %u sent %lu ms into a %lu ms cycle, outside slot %u; resyncing",
+         device.deviceId, (unsigned long)(nowMs % _slotCycleMs), (unsigned long)_slotCycleMs, device.uplinkSlot);
+    sendUplinkSlot(device, nowMs);
+}
+
+void RemoteDeviceManager::sendUplinkSlot(RemoteDeviceState& device, uint32_t nowMs) {
+    if (!_loraHal) return;
+    const uint32_t slotMs = _slotCycleMs / _slotCount;
+    const uint32_t target = device.uplinkSlot * slotMs + _slotGuardMs;
+    uint32_t delayMs = (target + _slotCycleMs - nowMs % _slotCycleMs) % _slotCycleMs;
+    if (delayMs < _slotGuardMs) delayMs += _slotCycleMs; // The remote has only just reported
+
+    // The delay is only right if it goes out now, so it jumps the outbox and
+    // is dropped if it can't leave within the guard time. An ACK retry would
+    // resend a stale delay too. Lost or late frames are caught by the next
+    // out-of-slot report instead.
+    const uint32_t cycleMs = _slotCycleMs;
+    uint8_t payload[] = {
+        (uint8_t)Messaging::CommandType::SetUplinkSlot,
+        (uint8_t)(delayMs >> 24), (uint8_t)(delayMs >> 16), (uint8_t)(delayMs >> 8), (uint8_t)delayMs,
+        (uint8_t)(cycleMs >> 24), (uint8_t)(cycleMs >> 16), (uint8_t)(cycleMs >> 8), (uint8_t)cycleMs
+    };
+    if (!_loraHal->sendDataWithin(device.deviceId, payload, sizeof(payload), _slotGuardMs)) {
+        LOGW("DeviceManager", "Could not queue uplink slot for device %u", device.deviceId);
+        return;
+    }
+    device.lastSlotSyncMs = nowMs;
+    LOGI("DeviceManager", "Sent uplink slot %u to device %u (next in %lu ms)", device.uplinkSlot, device.deviceId, (unsigned long)delayMs);
 }
 
 RemoteDeviceState* RemoteDeviceManager::getOrCreateDevice(uint8_t deviceId) {
@@ -9,12 +9,16 @@
 #include "lib/hal_lora.h"
 #include "lib/hal_wifi.h"
 #include "lib/hal_battery.h"
+#include "lib/hal_button.h"
 #include "lib/hal_persistence.h" // <-- Add include
 #include "lib/svc_ui.h"
 #include "lib/svc_comms.h"
 #include "lib/svc_battery.h"
 #include "lib/svc_wifi.h"
 #include "lib/svc_lora.h"
+#include "lib/svc_radio_config.h"
+#include "lib/board_config.h"
+#include "lib/telemetry_keys.h"
 
 #include "remote_sensor_config.h"
 #include "config.h"
@@ -50,6 +54,7 @@ private:
     std::unique_ptr<ILoRaHal> loraHal;
     std::unique_ptr<IWifiHal> wifiHal;
     std::unique_ptr<IBatteryHal> batteryHal;
+    std::unique_ptr<IButtonHal> buttonHal;
     std::unique_ptr<IPersistenceHal> persistenceHal; // <-- Add member
 
     std::unique_ptr<UiService> uiService;
@@ -57,6 +62,7 @@ private:
     std::unique_ptr<IBatteryService> batteryService;
     std::unique_ptr<IWifiService> wifiService;
     std::unique_ptr<ILoRaService> loraService;
+    std::unique_ptr<IRadioConfigService> radioConfigService;
 
     SensorManager sensorManager;
     std::unique_ptr<LoRaBatchTransmitter> sensorTransmitter;
@@ -82,6 +88,24 @@ private:
 
     uint32_t lastSuccessfulAckMs = 0;
 
+    // PRG-triggered range test; the result replaces the status text for a while
+    struct RangeTest {
+        uint32_t count = 0;
+        uint16_t msgId = 0;
+        bool pending = false;
+        uint32_t shownMs = 0;
+        char text[40] = {0};
+    } rangeTest;
+    static constexpr uint32_t kRangeTestShowMs = 10000;
+
+    // Uplink slot handed out by the relay; until then telemetry follows the
+    // local report interval
+    struct UplinkSlot {
+        bool assigned = false;
+        uint32_t nextTxMs = 0;
+        uint32_t cycleMs = 0;
+    } uplinkSlot;
+
     void onLoraAckReceived(uint8_t srcId, uint16_t messageId, uint8_t attempts);
     void onLoraMessageDropped(uint16_t messageId, uint8_t attempts);
     void onLoraDataReceived(uint8_t srcId, const uint8_t *payload, uint8_t length);
@@ -92,6 +116,8 @@ private:
 
     void setupUi();
     void setupSensors();
+    void sendRangeTest(uint32_t nowMs);
+    void queueSensorReadings(uint32_t nowMs);
 };
 
 RemoteApplicationImpl* RemoteApplicationImpl::callbackInstance = nullptr;
@@ -128,6 +154,7 @@ void RemoteApplicationImpl::initialize() {
     loraHal = std::make_unique<LoRaCommHal>();
     loraHal->setVerbose(config.communication.usb.verboseLogging);
     batteryHal = std::make_unique<BatteryMonitorHal>(config.battery);
+    buttonHal = std::make_unique<GpioButtonHal>(PRG_BUTTON_PIN);
 
     LOGI("Remote", "Creating services");
     uiService = std::make_unique<UiService>(*displayHal);
@@ -135,6 +162,7 @@ void RemoteApplicationImpl::initialize() {
     commsService->setLoraHal(loraHal.get());
     batteryService = std::make_unique<BatteryService>(*batteryHal);
     loraService = std::make_unique<LoRaService>(*loraHal);
+    radioConfigService = std::make_unique<RadioConfigService>(*loraHal, *persistenceHal, config.communication.lora, Serial);
 
     // Only create WiFi components if WiFi is enabled
     if (config.communication.wifi.enableWifi) {
@@ -152,8 +180,10 @@ void RemoteApplicationImpl::initialize() {
 
     LOGI("Remote", "Beginning hardware initialization");
     displayHal->begin();
+    buttonHal->begin();
     LOGI("Remote", "Display initialized");
 
+    radioConfigService->begin(); // Flash overrides must be in place before the radio starts
     loraHal->begin(ILoRaHal::Mode::Slave, config.deviceId);
     LOGI("Remote", "LoRa initialized");
     loraHal->setOnAckReceived(&RemoteApplicationImpl::staticOnAckReceived);
@@ -182,15 +212,8 @@ void RemoteApplicationImpl::initialize() {
     // Perform an initial sensor read and telemetry transmission
     if (sensorConfig.enableSensorSystem) {
         LOGI("Remote", "Performing initial sensor reading and telemetry transmission...");
-        auto readings = sensorManager.readAll();
-        readings.push_back({TelemetryKeys::ErrorCount, (float)_errorCount, millis()});
-        uint32_t timeSinceResetSec = (millis() - _lastResetMs) / 1000;
-        readings.push_back({TelemetryKeys::TimeSinceReset, (float)timeSinceResetSec, millis()});
-        if (sensorTransmitter) {
-            sensorTransmitter->queueBatch(readings);
-            // We can also trigger an immediate update attempt, which will be handled
-            // by the lora_tx task shortly anyway.
-        }
+        // Sent by the lora_tx task shortly
+        queueSensorReadings(millis());
     }
 
     LOGI("Remote", "Registering scheduler tasks");
@@ -220,7 +243,9 @@ void RemoteApplicationImpl::initialize() {
         batteryElement->setStatus(batteryService->getBatteryPercent(), batteryService->isCharging());
 
         // Update main status text with connection and error info
-        if (statusTextElement) {
+        if (statusTextElement && rangeTest.shownMs != 0 && state.nowMs - rangeTest.shownMs < kRangeTestShowMs) {
+            statusTextElement->setText(rangeTest.text);
+        } else if (statusTextElement) {
             char statusStr[32];
             const char* connStr = isConnected ? "Online" : "Offline";
             snprintf(statusStr, sizeof(statusStr), "%s\nErrors: %u", connStr, _errorCount);
@@ -237,20 +262,19 @@ void RemoteApplicationImpl::initialize() {
     if (sensorConfig.enableSensorSystem) {
         // This task reads sensors and fills the transmitter's buffer
         scheduler.registerTask("sensors", [this](CommonAppState& state){
-            auto readings = sensorManager.readAll();
-            
-            // Manually add application-level data to the batch
-            readings.push_back({TelemetryKeys::ErrorCount, (float)_errorCount, state.nowMs});
-            uint32_t timeSinceResetSec = (state.nowMs - _lastResetMs) / 1000;
-            readings.push_back({TelemetryKeys::TimeSinceReset, (float)timeSinceResetSec, state.nowMs});
-
-            if (sensorTransmitter) {
-                sensorTransmitter->queueBatch(readings);
-            }
+            if (uplinkSlot.assigned) return; // lora_tx reads at the start of the slot instead
+            queueSensorReadings(state.nowMs);
         }, config.globalDebugMode ? config.debugTelemetryReportIntervalMs : config.telemetryReportIntervalMs);
 
         // This task attempts to transmit the buffer
         scheduler.registerTask("lora_tx", [this](CommonAppState& state){
+            if (uplinkSlot.assigned && (int32_t)(state.nowMs - uplinkSlot.nextTxMs) >= 0) {
+                queueSensorReadings(state.nowMs);
+                // Step from the slot, not from now, so task jitter doesn't accumulate
+                do {
+                    uplinkSlot.nextTxMs += uplinkSlot.cycleMs;
+                } while ((int32_t)(state.nowMs - uplinkSlot.nextTxMs) >= 0);
+            }
             if (loraService->isConnected() && sensorTransmitter) {
                 sensorTransmitter->update(state.nowMs);
             }
@@ -264,6 +288,16 @@ void RemoteApplicationImpl::initialize() {
             lastSuccessfulAckMs = state.nowMs;
         }
     }, 30000); // Check every 30 seconds
+    scheduler.registerTask("radio_config", [this](CommonAppState& state){
+        radioConfigService->update(state.nowMs);
+    }, 100);
+
+    // PRG sends a range test frame to the relay
+    scheduler.registerTask("button", [this](CommonAppState& state){
+        if (buttonHal->wasPressed(state.nowMs)) {
+            sendRangeTest(state.nowMs);
+        }
+    }, 20);
 
 
     if (config.communication.wifi.enableWifi && wifiService) {
@@ -338,6 +372,26 @@ void RemoteApplicationImpl::setupSensors() {
     sensorManager.addSensor(waterFlowSensor);
 }
 
+void RemoteApplicationImpl::sendRangeTest(uint32_t nowMs) {
+    // "test:7,sf:9,txp:14" - the relay adds rssi/snr and publishes it on remote-{id}/test
+    const IRadioConfigService::RadioSettings& radio = radioConfigService->getSettings();
+    char payload[40];
+    const int length = snprintf(payload, sizeof(payload), "%s:%lu,%s:%u,%s:%d",
+                                TelemetryKeys::RangeTest, (unsigned long)(rangeTest.count + 1),
+                                TelemetryKeys::SpreadingFactor, (unsigned)radio.spreadingFactor,
+                                TelemetryKeys::TxPower, (int)radio.txPowerDbm);
+    if (!loraHal->sendData(config.masterNodeId, reinterpret_cast<const uint8_t*>(payload), (uint8_t)length, true)) {
+        snprintf(rangeTest.text, sizeof(rangeTest.text), "Test busy\ntry again");
+    } else {
+        rangeTest.count++;
+        rangeTest.msgId = loraHal->getLastQueuedMessageId();
+        rangeTest.pending = true;
+        snprintf(rangeTest.text, sizeof(rangeTest.text), "Test %lu\nsent", (unsigned long)rangeTest.count);
+        LOGI("Remote", "Range test %lu queued (msgId %u)", (unsigned long)rangeTest.count, rangeTest.msgId);
+    }
+    rangeTest.shownMs = nowMs;
+}
+
 void RemoteApplicationImpl::run() {
     // This run loop is for high-frequency, non-blocking tasks.
     // The main application logic is handled by the scheduler.
@@ -355,10 +409,39 @@ void RemoteApplicationImpl::onLoraAckReceived(uint8_t srcId, uint16_t messageId,
         loraStats.recovered++;
     }
     lastSuccessfulAckMs = millis();
+
+    if (rangeTest.pending && messageId == rangeTest.msgId) {
+        // Signal of the ACK as heard here; the relay reports the uplink side
+        const ILoRaHal::LinkStats link = loraHal->getLinkStats();
+        rangeTest.pending = false;
+        snprintf(rangeTest.text, sizeof(rangeTest.text), "Test %lu OK x%u\nRSSI %d SNR %d",
+                 (unsigned long)rangeTest.count, attempts, (int)link.lastRssiDbm, (int)link.lastSnrDb);
+        rangeTest.shownMs = millis();
+        LOGI("Remote", "Range test %lu ACKed: rssi=%d snr=%d attempts=%u",
+             (unsigned long)rangeTest.count, (int)link.lastRssiDbm, (int)link.lastSnrDb, attempts);
+    }
+}
+
+void RemoteApplicationImpl::queueSensorReadings(uint32_t nowMs) {
+    auto readings = sensorManager.readAll();
+
+    // Manually add application-level data to the batch
+    readings.push_back({TelemetryKeys::ErrorCount, (float)_errorCount, nowMs});
+    uint32_t timeSinceResetSec = (nowMs - _lastResetMs) / 1000;
+    readings.push_back({TelemetryKeys::TimeSinceReset, (float)timeSinceResetSec, nowMs});
+
+    if (sensorTransmitter) {
+        sensorTransmitter->queueBatch(readings);
+    }
 }
 
 void RemoteApplicationImpl::onLoraMessageDropped(uint16_t messageId, uint8_t attempts) {
     LOGW("Remote", "Message %u dropped after %u attempts", messageId, attempts);
+    if (rangeTest.pending && messageId == rangeTest.msgId) {
+        rangeTest.pending = false;
+        snprintf(rangeTest.text, sizeof(rangeTest.text), "Test %lu\nno ACK", (unsigned long)rangeTest.count);
+        rangeTest.shownMs = millis();
+    }
     loraStats.dropped++;
     _errorCount++;
     persistenceHal->begin("app_state");
@@ -388,6 +471,19 @@ void RemoteApplicationImpl::onLoraDataReceived(uint8_t srcId, const uint8_t *pay
             persistenceHal->saveU32("errorCount", _errorCount);
             persistenceHal->saveU32("lastResetMs", _lastResetMs);
             persistenceHal->end();
+        } else if (cmdType == Messaging::CommandType::SetUplinkSlot && length >= 9) {
+            const uint32_t delayMs = ((uint32_t)payload[1] << 24) | ((uint32_t)payload[2] << 16) |
+                                     ((uint32_t)payload[3] << 8) | (uint32_t)payload[4];
+            const uint32_t cycleMs = ((uint32_t)payload[5] << 24) | ((uint32_t)payload[6] << 16) |
+                                     ((uint32_t)payload[7] << 8) | (uint32_t)payload[8];
+            if (cycleMs == 0 || (uint64_t)delayMs > (uint64_t)cycleMs * 2) {
+                LOGW("Remote", "Ignoring uplink slot: delay %lu ms, cycle %lu ms", (unsigned long)delayMs, (unsigned long)cycleMs);
+                return;
+            }
+            uplinkSlot.nextTxMs = millis() + delayMs;
+            uplinkSlot.cycleMs = cycleMs;
+            uplinkSlot.assigned = true;
+            LOGI("Remote", "Uplink slot: next report in %lu ms, then every %lu ms", (unsigned long)delayMs, (unsigned long)cycleMs);
         }
     }
 }
@@ -425,6 +521,7 @@ void RemoteApplication::run() { impl->run(); }
 #include "lib/svc_battery.cpp"
 #include "lib/svc_wifi.cpp"
 #include "lib/svc_lora.cpp"
+#include "lib/svc_radio_config.cpp"
 #include "lib/ui_battery_icon_element.cpp"
 #include "lib/ui_header_status_element.cpp"
 #include "lib/ui_main_content_layout.cpp"
@@ -77,8 +77,9 @@ void LoRaBatchTransmitter::update(uint32_t nowMs) {
     }
     
     // The underlying LoRaComm library has a max payload size. We must respect it.
-    if (payload.length() > LORA_COMM_MAX_PAYLOAD) {
-        LOGW("LoRaBatchTransmitter", "Payload of %d bytes exceeds max of %d. Dropping batch.", payload.length(), LORA_COMM_MAX_PAYLOAD);
+    const uint8_t maxPayload = _loraHal->getMaxPayload();
+    if (payload.length() > maxPayload) {
+        LOGW("LoRaBatchTransmitter", "Payload of %d bytes exceeds max of %d. Dropping batch.", payload.length(), maxPayload);
         _readings.clear();
         return;
     }
@@ -0,0 +1,188 @@
+#!/bin/bash
+
+# Farm Monitoring System - Pi Health Telemetry
+#
+# Samples the Pi's own health and publishes it to the MQTT broker next to
+# the relay's telemetry, so a failing SD card, a hot SoC or a weak power
+# supply shows up before the gateway dies.
+#
+# Payload uses the relay's key:value format, e.g.
+#   up:86400,load:0.42,mem:37,disk:61,ro:0,temp:52.1,uv:0,uvh:0,thr:0
+#
+# Usage: ./pi_sysinfo.sh {publish|show|install|uninstall}
+
+# --- Terminal Colors ---
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[0;33m'
+BLUE='\033[0;34m'
+MAGENTA='\033[0;35m'
+CYAN='\033[0;36m'
+NC='\033[0m'
+BOLD='\033[1m'
+
+# --- Variables ---
+# Allow overrides via environment or simple .env-style file next to this script
+SCRIPT_PATH="$(readlink -f "$0")"
+ENV_FILE="$(dirname "$SCRIPT_PATH")/.pi_sysinfo.env"
+if [[ -f "$ENV_FILE" ]]; then
+  # shellcheck disable=SC1090
+  source "$ENV_FILE"
+fi
+
+MQTT_HOST="${MQTT_HOST:-localhost}"
+MQTT_PORT="${MQTT_PORT:-1883}"
+MQTT_TOPIC="${MQTT_TOPIC:-farm/telemetry/pi}" # Next to the relay's <baseTopic>/relay
+INTERVAL_SEC="${INTERVAL_SEC:-60}"
+BROKER_CONTAINER="${BROKER_CONTAINER:-farm-mosquitto}" # Fallback when mosquitto_pub isn't on the host
+UNIT_NAME="farm-pi-sysinfo"
+
+# --- Helper Functions ---
+log_error() { echo -e "${RED}[ERROR]${NC} $1" >&2; }
+log_info() { echo -e "${CYAN}[INFO]${NC} $1"; }
+log_success() { echo -e "${GREEN}[OK]${NC} $1"; }
+log_warning() { echo -e "${YELLOW}[WARN]${NC} $1"; }
+
+command_exists() { command -v "$1" >/dev/null 2>&1; }
+
+# --- Sampling ---
+
+# Prints one key:value line describing the Pi's health
+collect() {
+    local up load mem disk ro temp uv uvh thr
+    up=$(cut -d' ' -f1 /proc/uptime | cut -d'.' -f1)
+    load=$(cut -d' ' -f1 /proc/loadavg)
+
+    # Used memory in percent; MemAvailable counts reclaimable cache as free
+    mem=$(awk '/^MemTotal:/ {t=$2} /^MemAvailable:/ {a=$2} END {if (t > 0) printf "%d", (t - a) * 100 / t}' /proc/meminfo)
+
+    disk=$(df --output=pcent / | tail -n 1 | tr -dc '0-9')
+
+    # A dying SD card usually gets the root filesystem remounted read-only
+    ro=0
+    if awk '$2 == "/" {print $4}' /proc/mounts | grep -qE '(^|,)ro(,|$)'; then
+        ro=1
+    fi
+
+    local line="up:${up},load:${load},mem:${mem},disk:${disk},ro:${ro}"
+
+    if [[ -r /sys/class/thermal/thermal_zone0/temp ]]; then
+        temp=$(awk '{printf "%.1f", $1 / 1000}' /sys/class/thermal/thermal_zone0/temp)
+        line="${line},temp:${temp}"
+    fi
+
+    # Firmware flags: bit 0 undervoltage now, bit 2 throttled now, bit 16 undervoltage since boot
+    if command_exists vcgencmd; then
+        local flags
+        flags=$(vcgencmd get_throttled 2>/dev/null | cut -d'=' -f2)
+        if [[ -n "$flags" ]]; then
+            uv=$(( (flags >> 0) & 1 ))
+            thr=$(( (flags >> 2) & 1 ))
+            uvh=$(( (flags >> 16) & 1 ))
+            line="${line},uv:${uv},uvh:${uvh},thr:${thr}"
+        fi
+    fi
+
+    echo "$line"
+}
+
+# --- Main Functions ---
+
+publish() {
+    local payload
+    payload=$(collect)
+    if command_exists mosquitto_pub; then
+        mosquitto_pub -h "$MQTT_HOST" -p "$MQTT_PORT" -t "$MQTT_TOPIC" -m "$payload"
+    elif command_exists docker && docker ps --format '{{.Names}}' 2>/dev/null | grep -qx "$BROKER_CONTAINER"; then
+        docker exec "$BROKER_CONTAINER" mosquitto_pub -h localhost -p 1883 -t "$MQTT_TOPIC" -m "$payload"
+    else
+        log_error "Neither mosquitto_pub nor the '$BROKER_CONTAINER' container is available."
+        echo "Install the client with: sudo apt install -y mosquitto-clients"
+        exit 1
+    fi
+}
+
+show() {
+    echo -e "${BOLD}${BLUE}=== Pi Health ===${NC}"
+    log_info "Topic: ${BOLD}${MQTT_TOPIC}${NC} on ${MQTT_HOST}:${MQTT_PORT}"
+    collect
+}
+
+install_timer() {
+    if [[ $EUID -ne 0 ]]; then
+        log_error "Installing the timer requires root privileges. Please run with 'sudo'."
+        exit 1
+    fi
+    echo -e "${BOLD}${BLUE}=== Pi Health Timer Setup ===${NC}"
+
+    if ! command_exists mosquitto_pub; then
+        log_info "Installing mosquitto-clients..."
+        apt install -y mosquitto-clients || log_warning "Could not install mosquitto-clients; will publish through Docker"
+    fi
+
+    cat > "/etc/systemd/system/${UNIT_NAME}.service" <<EOF
+[Unit]
+Description=Publish Pi health telemetry to MQTT
+
+[Service]
+Type=oneshot
+ExecStart=${SCRIPT_PATH} publish
+EOF
+
+    cat > "/etc/systemd/system/${UNIT_NAME}.timer" <<EOF
+[Unit]
+Description=Publish Pi health telemetry every ${INTERVAL_SEC}s
+
+[Timer]
+OnBootSec=${INTERVAL_SEC}
+OnUnitActiveSec=${INTERVAL_SEC}
+AccuracySec=1s
+
+[Install]
+WantedBy=timers.target
+EOF
+
+    systemctl daemon-reload
+    systemctl enable --now "${UNIT_NAME}.timer"
+    log_success "Publishing to '${BOLD}${MQTT_TOPIC}${NC}' every ${INTERVAL_SEC}s"
+    echo -e "${YELLOW}To change the interval or topic, edit${NC} ${BOLD}${ENV_FILE}${NC} ${YELLOW}and run install again.${NC}"
+}
+
+uninstall_timer() {
+    if [[ $EUID -ne 0 ]]; then
+        log_error "Removing the timer requires root privileges. Please run with 'sudo'."
+        exit 1
+    fi
+    systemctl disable --now "${UNIT_NAME}.timer" 2>/dev/null || true
+    rm -f "/etc/systemd/system/${UNIT_NAME}.service" "/etc/systemd/system/${UNIT_NAME}.timer"
+    systemctl daemon-reload
+    log_success "Pi health timer removed"
+}
+
+# --- Main Script Logic ---
+
+case "${1:-}" in
+    publish)
+        publish
+        ;;
+    show)
+        show
+        ;;
+    install)
+        install_timer
+        ;;
+    uninstall)
+        uninstall_timer
+        ;;
+    *)
+        echo -e "${BOLD}${MAGENTA}Usage:${NC} $0 {publish|show|install|uninstall}"
+        echo ""
+        echo -e "${BOLD}Commands:${NC}"
+        echo -e "  ${GREEN}publish${NC}    - Sample once and publish to MQTT."
+        echo -e "  ${GREEN}show${NC}       - Sample once and print without publishing."
+        echo -e "  ${GREEN}install${NC}    - Publish every INTERVAL_SEC seconds from a systemd timer (sudo)."
+        echo -e "  ${GREEN}uninstall${NC}  - Remove the systemd timer (sudo)."
+        echo ""
+        exit 1
+        ;;
+esac
@@ -7,6 +7,7 @@
 # 2. Tailscale VPN installation and setup  
 # 3. Coolify installation for container management
 # 4. WiFi hotspot setup for Heltec device connectivity
+# 5. Pi health telemetry published to MQTT
 #
 # Usage: curl -sSL https://github.com/yourusername/farm/raw/main/edge/pi/setup_farm_pi.sh | bash
 
@@ -152,6 +153,15 @@ setup_wifi_hotspot() {
     log_info "Heltec devices can now connect to 'PiHotspot' network"
 }
 
+setup_pi_health() {
+    echo -e "${BOLD}${BLUE}=== Pi Health Telemetry ===${NC}"
+
+    chmod +x "$INSTALL_DIR/edge/pi/pi_sysinfo.sh"
+    sudo "$INSTALL_DIR/edge/pi/pi_sysinfo.sh" install
+
+    log_success "Pi health telemetry enabled"
+}
+
 verify_setup() {
     echo -e "${BOLD}${BLUE}=== Setup Verification ===${NC}"
     
@@ -175,6 +185,13 @@ verify_setup() {
     else
         log_warning "WiFi hotspot may not be properly configured"
     fi
+
+    # Check Pi health timer
+    if systemctl is-active --quiet farm-pi-sysinfo.timer; then
+        log_success "Pi health telemetry timer is active"
+    else
+        log_warning "Pi health telemetry timer is not active"
+    fi
     
     echo ""
     echo -e "${BOLD}${GREEN}=== Setup Summary ===${NC}"
@@ -212,6 +229,7 @@ main() {
     clone_repository
     setup_coolify
     setup_wifi_hotspot
+    setup_pi_health
     verify_setup
     
     echo -e "${BOLD}${GREEN}Setup complete! Your Pi is ready for farm monitoring.${NC}"